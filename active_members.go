@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+)
+
+// printActiveMembers prints most active posters of chat since given time,
+// scanning at most limit messages of history.
+func printActiveMembers(ctx context.Context, api *tg.Client, p tg.InputPeerClass, since time.Time, limit int) error {
+	type member struct {
+		id    int64
+		name  string
+		count int
+	}
+	members := map[int64]*member{}
+
+	scanned := 0
+	partial := false
+	iter := query.Messages(api).GetHistory(p).BatchSize(100).Iter()
+	for iter.Next(ctx) {
+		elem := iter.Value()
+		if int64(elem.Msg.GetDate()) < since.Unix() {
+			break
+		}
+		if scanned >= limit {
+			partial = true
+			break
+		}
+		scanned++
+
+		msg, ok := elem.Msg.(*tg.Message)
+		if !ok {
+			continue
+		}
+		from, ok := msg.FromID.(*tg.PeerUser)
+		if !ok {
+			continue
+		}
+		m, ok := members[from.UserID]
+		if !ok {
+			m = &member{id: from.UserID, name: fmt.Sprintf("user %d", from.UserID)}
+			if u, ok := elem.Entities.User(from.UserID); ok {
+				m.name = u.FirstName
+				if u.Username != "" {
+					m.name += " (@" + u.Username + ")"
+				}
+			}
+			members[from.UserID] = m
+		}
+		m.count++
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "iterate history")
+	}
+
+	sorted := make([]*member, 0, len(members))
+	for _, m := range members {
+		sorted = append(sorted, m)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].count > sorted[j].count
+	})
+	if len(sorted) > 20 {
+		sorted = sorted[:20]
+	}
+
+	fmt.Printf("Scanned %d messages since %s\n", scanned, since.Format(time.DateTime))
+	if partial {
+		fmt.Printf("Partial result: scan limit of %d messages reached\n", limit)
+	}
+	if len(sorted) == 0 {
+		fmt.Println("No posts from users")
+		return nil
+	}
+	for i, m := range sorted {
+		fmt.Printf("%2d. %s: %d messages\n", i+1, m.name, m.count)
+	}
+	return nil
+}
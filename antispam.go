@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// Anti-spam actions.
+const (
+	spamLog      = ""         // only log
+	spamRestrict = "restrict" // restrict sending messages
+	spamDelete   = "delete"   // delete message
+)
+
+// maxSpamTracked limits number of tracked senders.
+const maxSpamTracked = 10000
+
+type senderKey struct {
+	chat int64
+	user int64
+}
+
+// senderRate is exponentially decaying message count.
+type senderRate struct {
+	count float64
+	last  time.Time
+}
+
+// antiSpam tracks per-sender message rates in group chats and handles
+// senders exceeding limit.
+type antiSpam struct {
+	api *tg.Client
+	lg  *zap.Logger
+
+	limit    float64
+	window   time.Duration
+	action   string
+	restrict time.Duration
+
+	mux     sync.Mutex
+	senders map[senderKey]*senderRate
+}
+
+func newAntiSpam(api *tg.Client, lg *zap.Logger, limit int, window time.Duration, action string, restrict time.Duration) (*antiSpam, error) {
+	switch action {
+	case spamLog, spamRestrict, spamDelete:
+	default:
+		return nil, errors.Errorf("unknown anti-spam action %q", action)
+	}
+	if window <= 0 {
+		return nil, errors.New("anti-spam window should be positive")
+	}
+	if _, err := parseBannedRights("send", restrict); err != nil {
+		return nil, errors.Wrap(err, "restriction")
+	}
+	return &antiSpam{
+		api:      api,
+		lg:       lg,
+		limit:    float64(limit),
+		window:   window,
+		action:   action,
+		restrict: restrict,
+		senders:  map[senderKey]*senderRate{},
+	}, nil
+}
+
+// observe records message and reports whether sender exceeded limit.
+func (a *antiSpam) observe(k senderKey, now time.Time) bool {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	r, ok := a.senders[k]
+	if !ok {
+		if len(a.senders) >= maxSpamTracked {
+			a.evict(now)
+		}
+		r = &senderRate{last: now}
+		a.senders[k] = r
+	}
+	r.count = r.count*a.decay(now.Sub(r.last)) + 1
+	r.last = now
+	if r.count <= a.limit {
+		return false
+	}
+	// Handling sender once per exceeding.
+	r.count = 0
+	return true
+}
+
+func (a *antiSpam) decay(d time.Duration) float64 {
+	return math.Exp(-float64(d) / float64(a.window))
+}
+
+// evict removes senders with decayed rate, or all senders if none decayed.
+func (a *antiSpam) evict(now time.Time) {
+	for k, r := range a.senders {
+		if r.count*a.decay(now.Sub(r.last)) < 1 {
+			delete(a.senders, k)
+		}
+	}
+	if len(a.senders) >= maxSpamTracked {
+		a.senders = map[senderKey]*senderRate{}
+	}
+}
+
+// isAdmin reports whether user is admin of channel.
+func (a *antiSpam) isAdmin(ctx context.Context, channel tg.InputChannelClass, user tg.InputPeerClass) (bool, error) {
+	res, err := a.api.ChannelsGetParticipant(ctx, &tg.ChannelsGetParticipantRequest{
+		Channel:     channel,
+		Participant: user,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "get participant")
+	}
+	switch res.Participant.(type) {
+	case *tg.ChannelParticipantAdmin, *tg.ChannelParticipantCreator:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// Handle tracks incoming message in chat and handles spam.
+func (a *antiSpam) Handle(ctx context.Context, chat tg.InputPeerClass, msg *tg.Message) error {
+	from, ok := msg.FromID.(*tg.PeerUser)
+	if !ok || msg.Out {
+		return nil
+	}
+	var chatID int64
+	switch p := chat.(type) {
+	case *tg.InputPeerChat:
+		chatID = p.ChatID
+	case *tg.InputPeerChannel:
+		chatID = p.ChannelID
+	default:
+		// Not a group chat.
+		return nil
+	}
+	if !a.observe(senderKey{chat: chatID, user: from.UserID}, time.Now()) {
+		return nil
+	}
+
+	user := &tg.InputPeerUserFromMessage{
+		Peer:   chat,
+		MsgID:  msg.ID,
+		UserID: from.UserID,
+	}
+	channel, isChannel := peer.ToInputChannel(chat)
+	if isChannel {
+		admin, err := a.isAdmin(ctx, channel, user)
+		if err != nil {
+			return err
+		}
+		if admin {
+			return nil
+		}
+	}
+
+	a.lg.Warn("Spam detected",
+		zap.Int64("chat_id", chatID),
+		zap.Int64("user_id", from.UserID),
+		zap.String("action", a.action),
+	)
+	fmt.Printf("Spam from user %d in chat %d\n", from.UserID, chatID)
+	if a.action == spamLog {
+		return nil
+	}
+	if !isChannel {
+		// Basic groups do not support restrictions and moderation by
+		// admins other than creator is limited.
+		a.lg.Info("Anti-spam action is supported only in supergroups")
+		return nil
+	}
+
+	switch a.action {
+	case spamRestrict:
+		rights, err := parseBannedRights("send", a.restrict)
+		if err != nil {
+			return err
+		}
+		if err := editBanned(ctx, a.api, chat, user, rights); err != nil {
+			return errors.Wrap(err, "restrict spammer")
+		}
+	case spamDelete:
+		if _, err := a.api.ChannelsDeleteMessages(ctx, &tg.ChannelsDeleteMessagesRequest{
+			Channel: channel,
+			ID:      []int{msg.ID},
+		}); err != nil {
+			return errors.Wrap(err, "delete spam")
+		}
+	}
+	return nil
+}
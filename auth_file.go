@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+// waitFilePoll is interval of checking file for input.
+const waitFilePoll = 500 * time.Millisecond
+
+// waitFile waits for input in file or named pipe, failing after timeout
+// if it is positive.
+//
+// Regular file is polled until it is not empty and removed after reading,
+// so stale input is not reused on next login.
+func waitFile(ctx context.Context, path string, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(waitFilePoll)
+	defer ticker.Stop()
+	for {
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+			return readPipe(ctx, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return "", errors.Wrap(err, "read")
+		}
+		if v := strings.TrimSpace(string(data)); v != "" {
+			if err := os.Remove(path); err != nil {
+				return "", errors.Wrap(err, "remove")
+			}
+			return v, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", errors.Wrapf(ctx.Err(), "wait for %s", path)
+		case <-ticker.C:
+		}
+	}
+}
+
+// readPipe reads input from named pipe until context is done.
+func readPipe(ctx context.Context, path string) (string, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	// Opening pipe blocks until writer is connected and can't be
+	// cancelled, so reading in background.
+	done := make(chan result, 1)
+	go func() {
+		data, err := os.ReadFile(path)
+		done <- result{data: data, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", errors.Wrapf(ctx.Err(), "wait for %s", path)
+	case r := <-done:
+		if r.err != nil {
+			return "", errors.Wrap(r.err, "read pipe")
+		}
+		return strings.TrimSpace(string(r.data)), nil
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// backlogReportInterval is interval of backlog recovery progress reports.
+const backlogReportInterval = 5 * time.Second
+
+// backlog detects burst of old updates recovered after downtime.
+//
+// Message older than age is considered recovered from backlog, so side
+// effects like printing, auto-reactions and marking as read are skipped
+// for it, and only progress is reported. Processing is back to normal on
+// first fresh message.
+type backlog struct {
+	age time.Duration
+	lg  *zap.Logger
+
+	mux        sync.Mutex
+	active     bool
+	count      int
+	lastReport time.Time
+}
+
+func newBacklog(lg *zap.Logger, age time.Duration) *backlog {
+	return &backlog{age: age, lg: lg}
+}
+
+// Observe reports whether message with given date is from backlog.
+func (b *backlog) Observe(date int, now time.Time) bool {
+	if b.age <= 0 {
+		return false
+	}
+	old := now.Sub(time.Unix(int64(date), 0)) > b.age
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if !old {
+		if b.active {
+			b.lg.Info("Backlog recovered", zap.Int("count", b.count))
+			fmt.Printf("Backlog recovered: %d updates processed\n", b.count)
+			b.active = false
+			b.count = 0
+		}
+		return false
+	}
+	if !b.active {
+		b.lg.Info("Recovering backlog", zap.Duration("age", b.age))
+		b.active = true
+		b.lastReport = now
+	}
+	b.count++
+	if now.Sub(b.lastReport) >= backlogReportInterval {
+		fmt.Printf("Recovering backlog: %d updates processed\n", b.count)
+		b.lastReport = now
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/tg"
+)
+
+// Telegram considers restrictions shorter than 30 seconds or longer than
+// 366 days as permanent.
+const (
+	minBanDuration = 30 * time.Second
+	maxBanDuration = 366 * 24 * time.Hour
+)
+
+// parseBannedRights parses comma-separated list of restricted rights.
+//
+// The "view" right means full ban: user is removed from the channel and
+// can't join again.
+func parseBannedRights(s string, duration time.Duration) (tg.ChatBannedRights, error) {
+	if duration != 0 && (duration < minBanDuration || duration > maxBanDuration) {
+		return tg.ChatBannedRights{}, errors.Errorf(
+			"duration %s is out of range [%s, %s], use 0 for permanent", duration, minBanDuration, maxBanDuration,
+		)
+	}
+
+	var rights tg.ChatBannedRights
+	for _, r := range strings.Split(s, ",") {
+		switch strings.TrimSpace(r) {
+		case "view":
+			rights.ViewMessages = true
+		case "send":
+			rights.SendMessages = true
+		case "media":
+			rights.SendMedia = true
+		case "stickers":
+			rights.SendStickers = true
+		case "gifs":
+			rights.SendGifs = true
+		case "inline":
+			rights.SendInline = true
+		case "links":
+			rights.EmbedLinks = true
+		case "polls":
+			rights.SendPolls = true
+		case "info":
+			rights.ChangeInfo = true
+		case "invite":
+			rights.InviteUsers = true
+		case "pin":
+			rights.PinMessages = true
+		default:
+			return tg.ChatBannedRights{}, errors.Errorf("unknown right %q", r)
+		}
+	}
+	if duration != 0 {
+		rights.UntilDate = int(time.Now().Add(duration).Unix())
+	}
+	return rights, nil
+}
+
+// editBanned sets banned rights of user in channel. Empty rights lift all
+// restrictions.
+func editBanned(ctx context.Context, api *tg.Client, channel, user tg.InputPeerClass, rights tg.ChatBannedRights) error {
+	inputChannel, ok := peer.ToInputChannel(channel)
+	if !ok {
+		return errors.New("not a channel")
+	}
+	if _, err := api.ChannelsEditBanned(ctx, &tg.ChannelsEditBannedRequest{
+		Channel:      inputChannel,
+		Participant:  user,
+		BannedRights: rights,
+	}); err != nil {
+		return errors.Wrap(err, "edit banned")
+	}
+	return nil
+}
+
+// banUser restricts user in channel after confirmation.
+func banUser(ctx context.Context, api *tg.Client, channel, user tg.InputPeerClass, rights tg.ChatBannedRights) error {
+	until := "forever"
+	if rights.UntilDate != 0 {
+		until = "until " + time.Unix(int64(rights.UntilDate), 0).Format(time.RFC3339)
+	}
+	ok, err := confirm(fmt.Sprintf("Restrict user %s", until))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Cancelled")
+		return nil
+	}
+	if err := editBanned(ctx, api, channel, user, rights); err != nil {
+		return err
+	}
+	fmt.Println("Restricted")
+	return nil
+}
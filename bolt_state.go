@@ -228,3 +228,81 @@ func (s *BoltState) ForEachChannels(ctx context.Context, userID int64, f func(ct
 		})
 	})
 }
+
+// StateDump is full updates state of account, used to move state between
+// machines.
+type StateDump struct {
+	UserID   int64         `json:"user_id"`
+	Pts      int           `json:"pts"`
+	Qts      int           `json:"qts"`
+	Date     int           `json:"date"`
+	Seq      int           `json:"seq"`
+	Channels map[int64]int `json:"channels"`
+}
+
+// Export returns full updates state of user.
+func (s *BoltState) Export(ctx context.Context, userID int64) (StateDump, error) {
+	state, found, err := s.GetState(ctx, userID)
+	if err != nil {
+		return StateDump{}, err
+	}
+	if !found {
+		return StateDump{}, fmt.Errorf("state not found")
+	}
+	dump := StateDump{
+		UserID:   userID,
+		Pts:      state.Pts,
+		Qts:      state.Qts,
+		Date:     state.Date,
+		Seq:      state.Seq,
+		Channels: map[int64]int{},
+	}
+	if err := s.ForEachChannels(ctx, userID, func(ctx context.Context, channelID int64, pts int) error {
+		dump.Channels[channelID] = pts
+		return nil
+	}); err != nil {
+		return StateDump{}, err
+	}
+	return dump, nil
+}
+
+// Import replaces updates state of user with given one.
+func (s *BoltState) Import(ctx context.Context, dump StateDump) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(i642b(dump.UserID)) != nil {
+			if err := tx.DeleteBucket(i642b(dump.UserID)); err != nil {
+				return err
+			}
+		}
+		user, err := tx.CreateBucket(i642b(dump.UserID))
+		if err != nil {
+			return err
+		}
+
+		state, err := user.CreateBucket([]byte("state"))
+		if err != nil {
+			return err
+		}
+		for k, v := range map[string]int{
+			"pts":  dump.Pts,
+			"qts":  dump.Qts,
+			"date": dump.Date,
+			"seq":  dump.Seq,
+		} {
+			if err := state.Put([]byte(k), i2b(v)); err != nil {
+				return err
+			}
+		}
+
+		channels, err := user.CreateBucket([]byte("channels"))
+		if err != nil {
+			return err
+		}
+		for channelID, pts := range dump.Channels {
+			if err := channels.Put(i642b(channelID), i2b(pts)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/binary"
+	"time"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+	"go.uber.org/multierr"
+)
+
+// bookmarkKey is key of processing bookmark in pebble database.
+var bookmarkKey = []byte("meta/processed_bookmark")
+
+// setBookmark persists processing bookmark: messages sent before t are
+// considered processed.
+func setBookmark(db *pebbledb.DB, t time.Time) error {
+	data := binary.BigEndian.AppendUint64(nil, uint64(t.Unix()))
+	if err := db.Set(bookmarkKey, data, pebbledb.Sync); err != nil {
+		return errors.Wrap(err, "set bookmark")
+	}
+	return nil
+}
+
+// loadBookmark loads processing bookmark, returning zero time if not set.
+func loadBookmark(db *pebbledb.DB) (_ time.Time, rerr error) {
+	data, closer, err := db.Get(bookmarkKey)
+	if errors.Is(err, pebbledb.ErrNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "get bookmark")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, closer.Close())
+	}()
+	if len(data) != 8 {
+		return time.Time{}, errors.Errorf("invalid bookmark length %d", len(data))
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(data)), 0), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/tg"
+)
+
+// printBotInfo prints public info of given bot: description, about text
+// and list of commands.
+func printBotInfo(ctx context.Context, api *tg.Client, p tg.InputPeerClass) error {
+	user, ok := peer.ToInputUser(p)
+	if !ok {
+		return errors.New("not a bot: peer is not a user")
+	}
+	full, err := api.UsersGetFullUser(ctx, user)
+	if err != nil {
+		return errors.Wrap(err, "get full user")
+	}
+
+	var bot *tg.User
+	for _, u := range full.Users {
+		if u, ok := u.(*tg.User); ok && u.ID == full.FullUser.ID {
+			bot = u
+		}
+	}
+	if bot == nil || !bot.Bot {
+		return errors.New("not a bot")
+	}
+	info, ok := full.FullUser.GetBotInfo()
+	if !ok {
+		return errors.New("no bot info")
+	}
+
+	fmt.Printf("Bot: %s (@%s)\n", bot.FirstName, bot.Username)
+	if about, ok := full.FullUser.GetAbout(); ok {
+		fmt.Println("About:", about)
+	}
+	if info.Description != "" {
+		fmt.Println("Description:", info.Description)
+	}
+	if len(info.Commands) == 0 {
+		fmt.Println("No commands")
+		return nil
+	}
+	fmt.Println("Commands:")
+	for _, c := range info.Commands {
+		fmt.Printf("  /%s - %s\n", c.Command, c.Description)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+)
+
+// chatTheme prints theme of dialog with given peer.
+//
+// Theme is stored in full peer info, so it requires separate request
+// per peer kind. Per-chat wallpapers are not available in current API
+// layer, so only theme emoticon is reported.
+func chatTheme(ctx context.Context, api *tg.Client, p tg.InputPeerClass) error {
+	var emoticon string
+	switch p := p.(type) {
+	case *tg.InputPeerUser:
+		full, err := api.UsersGetFullUser(ctx, &tg.InputUser{
+			UserID:     p.UserID,
+			AccessHash: p.AccessHash,
+		})
+		if err != nil {
+			return errors.Wrap(err, "get full user")
+		}
+		emoticon, _ = full.FullUser.GetThemeEmoticon()
+	case *tg.InputPeerSelf:
+		full, err := api.UsersGetFullUser(ctx, &tg.InputUserSelf{})
+		if err != nil {
+			return errors.Wrap(err, "get full user")
+		}
+		emoticon, _ = full.FullUser.GetThemeEmoticon()
+	case *tg.InputPeerChat:
+		full, err := api.MessagesGetFullChat(ctx, p.ChatID)
+		if err != nil {
+			return errors.Wrap(err, "get full chat")
+		}
+		emoticon, _ = full.FullChat.GetThemeEmoticon()
+	case *tg.InputPeerChannel:
+		full, err := api.ChannelsGetFullChannel(ctx, &tg.InputChannel{
+			ChannelID:  p.ChannelID,
+			AccessHash: p.AccessHash,
+		})
+		if err != nil {
+			return errors.Wrap(err, "get full channel")
+		}
+		emoticon, _ = full.FullChat.GetThemeEmoticon()
+	default:
+		return errors.Errorf("unexpected peer %T", p)
+	}
+
+	if emoticon == "" {
+		fmt.Println("Chat theme: default (no custom theme)")
+		return nil
+	}
+	fmt.Println("Chat theme:", emoticon)
+	return nil
+}
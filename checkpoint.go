@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/updates"
+	"github.com/gotd/td/tg"
+)
+
+var (
+	_ updates.StateStorage   = (*Checkpoint)(nil)
+	_ telegram.UpdateHandler = (*Checkpoint)(nil)
+)
+
+// Checkpoint provides strictly ordered at-least-once processing of updates.
+//
+// Updates manager persists pts even if handler failed, so failed update is
+// lost after restart. Checkpoint wraps both handler and state storage of
+// updates manager: state is persisted only while all updates are handled
+// successfully, i.e. persisted pts is a checkpoint of processed updates.
+//
+// On first handler error, checkpoint freezes persisted state and stops
+// updates manager, so no updates after failed one are processed. When
+// manager is started again, it resumes from the last checkpoint and
+// fetches failed update again via getDifference.
+type Checkpoint struct {
+	updates.StateStorage
+	handler telegram.UpdateHandler
+
+	mux    sync.Mutex
+	err    error
+	cancel context.CancelFunc
+}
+
+// NewCheckpoint creates new Checkpoint.
+func NewCheckpoint(handler telegram.UpdateHandler, storage updates.StateStorage) *Checkpoint {
+	return &Checkpoint{
+		StateStorage: storage,
+		handler:      handler,
+	}
+}
+
+// Run calls f (that should run updates manager) with context that is
+// cancelled on first handler error.
+func (c *Checkpoint) Run(ctx context.Context, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.mux.Lock()
+	c.err = nil
+	c.cancel = cancel
+	c.mux.Unlock()
+
+	runErr := f(ctx)
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.cancel = nil
+	if c.err != nil {
+		return errors.Wrap(c.err, "handle updates")
+	}
+	return runErr
+}
+
+// Handle implements telegram.UpdateHandler.
+func (c *Checkpoint) Handle(ctx context.Context, u tg.UpdatesClass) error {
+	if err := c.frozen(); err != nil {
+		// Not processing anything after failed update.
+		return err
+	}
+	err := c.handler.Handle(ctx, u)
+	if err == nil {
+		return nil
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return err
+}
+
+func (c *Checkpoint) frozen() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.err
+}
+
+func (c *Checkpoint) SetState(ctx context.Context, userID int64, state updates.State) error {
+	if c.frozen() != nil {
+		return nil
+	}
+	return c.StateStorage.SetState(ctx, userID, state)
+}
+
+func (c *Checkpoint) SetPts(ctx context.Context, userID int64, pts int) error {
+	if c.frozen() != nil {
+		return nil
+	}
+	return c.StateStorage.SetPts(ctx, userID, pts)
+}
+
+func (c *Checkpoint) SetQts(ctx context.Context, userID int64, qts int) error {
+	if c.frozen() != nil {
+		return nil
+	}
+	return c.StateStorage.SetQts(ctx, userID, qts)
+}
+
+func (c *Checkpoint) SetDate(ctx context.Context, userID int64, date int) error {
+	if c.frozen() != nil {
+		return nil
+	}
+	return c.StateStorage.SetDate(ctx, userID, date)
+}
+
+func (c *Checkpoint) SetSeq(ctx context.Context, userID int64, seq int) error {
+	if c.frozen() != nil {
+		return nil
+	}
+	return c.StateStorage.SetSeq(ctx, userID, seq)
+}
+
+func (c *Checkpoint) SetDateSeq(ctx context.Context, userID int64, date, seq int) error {
+	if c.frozen() != nil {
+		return nil
+	}
+	return c.StateStorage.SetDateSeq(ctx, userID, date, seq)
+}
+
+func (c *Checkpoint) SetChannelPts(ctx context.Context, userID, channelID int64, pts int) error {
+	if c.frozen() != nil {
+		return nil
+	}
+	return c.StateStorage.SetChannelPts(ctx, userID, channelID, pts)
+}
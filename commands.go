@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/tg"
+)
+
+// commandRouter dispatches slash-commands of incoming messages by leading
+// token, like "/ping".
+type commandRouter struct {
+	api       *tg.Client
+	peerDB    storage.PeerStorage
+	sender    *message.Sender
+	parseMode string
+	handlers  map[string]func(ctx context.Context, msg *tg.Message) error
+}
+
+// builtinCommands are descriptions of commands handled by default.
+var builtinCommands = []struct {
+	cmd, desc string
+}{
+	{"/ping", "reply with pong"},
+	{"/id", "print ID of this chat"},
+	{"/whoami", "print your ID"},
+	{"/react", "react to replied message with given emoji"},
+	{"/help", "print this help"},
+}
+
+// helpText returns help of builtin commands in given parse mode.
+func helpText(mode string) string {
+	var b strings.Builder
+	switch mode {
+	case "markdown":
+		b.WriteString("**Commands**\n")
+	case "html":
+		b.WriteString("<b>Commands</b>\n")
+	default:
+		b.WriteString("Commands:\n")
+	}
+	for _, c := range builtinCommands {
+		switch mode {
+		case "markdown":
+			fmt.Fprintf(&b, "`%s` - %s\n", c.cmd, c.desc)
+		case "html":
+			fmt.Fprintf(&b, "<code>%s</code> - %s\n", c.cmd, c.desc)
+		default:
+			fmt.Fprintf(&b, "%s - %s\n", c.cmd, c.desc)
+		}
+	}
+	return b.String()
+}
+
+// newCommandRouter creates router of builtin commands, replies are parsed
+// with given parse mode (markdown, html or empty for plain text).
+func newCommandRouter(api *tg.Client, peerDB storage.PeerStorage, sender *message.Sender, parseMode string) *commandRouter {
+	r := &commandRouter{
+		api:       api,
+		peerDB:    peerDB,
+		sender:    sender,
+		parseMode: parseMode,
+		handlers:  map[string]func(ctx context.Context, msg *tg.Message) error{},
+	}
+	r.Handle("/help", func(ctx context.Context, msg *tg.Message) error {
+		return r.withTyping(ctx, msg, func(ctx context.Context) error {
+			return r.replyStyled(ctx, msg, helpText(parseMode))
+		})
+	})
+	r.Handle("/ping", func(ctx context.Context, msg *tg.Message) error {
+		return r.reply(ctx, msg, "pong")
+	})
+	r.Handle("/id", func(ctx context.Context, msg *tg.Message) error {
+		return r.answer(ctx, msg, fmt.Sprintf("Chat ID: %d", peerClassID(msg.PeerID)))
+	})
+	r.Handle("/whoami", func(ctx context.Context, msg *tg.Message) error {
+		from, ok := msg.GetFromID()
+		if !ok {
+			// Private chat, sender is the peer.
+			from = msg.PeerID
+		}
+		return r.answer(ctx, msg, fmt.Sprintf("Your ID: %d", peerClassID(from)))
+	})
+	r.Handle("/react", func(ctx context.Context, msg *tg.Message) error {
+		h, ok := msg.GetReplyTo()
+		if !ok {
+			return r.reply(ctx, msg, "Reply to message to react to it")
+		}
+		reaction := defaultReaction
+		if fields := strings.Fields(msg.Message); len(fields) > 1 {
+			reaction = fields[1]
+		}
+		p, err := storage.FindPeer(ctx, r.peerDB, msg.PeerID)
+		if err != nil {
+			return errors.Wrap(err, "find peer")
+		}
+		sent, err := sendReaction(ctx, r.api, p.AsInputPeer(), h.ReplyToMsgID, reaction)
+		if err != nil {
+			return err
+		}
+		if !sent {
+			return r.reply(ctx, msg, "Reaction is not allowed in this chat")
+		}
+		return nil
+	})
+	return r
+}
+
+// defaultReaction is reaction of /react without arguments.
+const defaultReaction = "👍"
+
+// Handle registers handler of command.
+func (r *commandRouter) Handle(cmd string, fn func(ctx context.Context, msg *tg.Message) error) {
+	r.handlers[cmd] = fn
+}
+
+// Route calls handler of command in message, if any, reporting whether
+// message is a command.
+//
+// Outgoing messages and unknown commands are ignored.
+func (r *commandRouter) Route(ctx context.Context, msg *tg.Message) (bool, error) {
+	if msg.Out {
+		return false, nil
+	}
+	fields := strings.Fields(msg.Message)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return false, nil
+	}
+	// Commands in groups can be addressed to bot, like "/ping@bot".
+	cmd, _, _ := strings.Cut(fields[0], "@")
+	fn, ok := r.handlers[cmd]
+	if !ok {
+		return false, nil
+	}
+	if err := fn(ctx, msg); err != nil {
+		return true, errors.Wrapf(err, "command %s", cmd)
+	}
+	return true, nil
+}
+
+// answer sends text to chat of message.
+func (r *commandRouter) answer(ctx context.Context, msg *tg.Message, text string) error {
+	return r.send(ctx, msg, &tg.MessagesSendMessageRequest{Message: text})
+}
+
+// reply sends text to chat of message as reply to it.
+func (r *commandRouter) reply(ctx context.Context, msg *tg.Message, text string) error {
+	req := &tg.MessagesSendMessageRequest{Message: text}
+	req.SetReplyToMsgID(msg.ID)
+	if _, ok := msg.PeerID.(*tg.PeerChannel); ok {
+		// Message in forum topic of supergroup, reply should be in the
+		// same topic.
+		if h, ok := msg.GetReplyTo(); ok && h.ForumTopic {
+			topID, ok := h.GetReplyToTopID()
+			if !ok {
+				// Reply to topic start message.
+				topID = h.ReplyToMsgID
+			}
+			req.SetTopMsgID(topID)
+		}
+	}
+	return r.send(ctx, msg, req)
+}
+
+// replyStyled replies to message with text in parse mode of router.
+func (r *commandRouter) replyStyled(ctx context.Context, msg *tg.Message, text string) error {
+	if r.parseMode == "" {
+		return r.reply(ctx, msg, text)
+	}
+	opts, err := parseText(ctx, r.peerDB, r.parseMode, text)
+	if err != nil {
+		return errors.Wrap(err, "parse")
+	}
+	p, err := storage.FindPeer(ctx, r.peerDB, msg.PeerID)
+	if err != nil {
+		return errors.Wrap(err, "find peer")
+	}
+	// Reply to message in forum topic is sent to the same topic.
+	if _, err := r.sender.To(p.AsInputPeer()).Reply(msg.ID).StyledText(ctx, opts...); err != nil {
+		return errors.Wrap(err, "send")
+	}
+	return nil
+}
+
+// send sends message to chat of msg.
+func (r *commandRouter) send(ctx context.Context, msg *tg.Message, req *tg.MessagesSendMessageRequest) error {
+	p, err := storage.FindPeer(ctx, r.peerDB, msg.PeerID)
+	if err != nil {
+		return errors.Wrap(err, "find peer")
+	}
+	req.Peer = p.AsInputPeer()
+	if err := binary.Read(rand.Reader, binary.LittleEndian, &req.RandomID); err != nil {
+		return errors.Wrap(err, "random id")
+	}
+	if _, err := r.api.MessagesSendMessage(ctx, req); err != nil {
+		return errors.Wrap(err, "send")
+	}
+	return nil
+}
+
+// typingInterval is interval of typing action refresh, action expires in
+// about 5 seconds on clients.
+const typingInterval = 4 * time.Second
+
+// withTyping shows typing action in chat of message until fn returns,
+// then cancels it.
+//
+// Handlers of commands that compose reply can wrap their work, so chat
+// does not look dead before reply.
+func (r *commandRouter) withTyping(parent context.Context, msg *tg.Message, fn func(ctx context.Context) error) error {
+	peer, err := storage.FindPeer(parent, r.peerDB, msg.PeerID)
+	if err != nil {
+		return errors.Wrap(err, "find peer")
+	}
+	p := peer.AsInputPeer()
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(typingInterval)
+		defer ticker.Stop()
+		for {
+			// Typing action is best-effort.
+			_, _ = r.api.MessagesSetTyping(ctx, &tg.MessagesSetTypingRequest{
+				Peer:   p,
+				Action: &tg.SendMessageTypingAction{},
+			})
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	err = fn(ctx)
+	cancel()
+	<-done
+	// Otherwise typing is shown for several seconds after reply.
+	_, _ = r.api.MessagesSetTyping(parent, &tg.MessagesSetTypingRequest{
+		Peer:   p,
+		Action: &tg.SendMessageCancelAction{},
+	})
+	return err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+)
+
+// discussion returns peer of linked discussion group and ID of message that
+// starts comment thread of given channel post.
+func discussion(ctx context.Context, api *tg.Client, channel tg.InputPeerClass, postID int) (tg.InputPeerClass, int, error) {
+	res, err := api.MessagesGetDiscussionMessage(ctx, &tg.MessagesGetDiscussionMessageRequest{
+		Peer:  channel,
+		MsgID: postID,
+	})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "get discussion message")
+	}
+	if len(res.Messages) == 0 {
+		return nil, 0, errors.New("post has no comments thread")
+	}
+
+	// Thread is started by the copy of post in discussion group.
+	thread, ok := res.Messages[0].(*tg.Message)
+	if !ok {
+		return nil, 0, errors.Errorf("unexpected thread message %T", res.Messages[0])
+	}
+	group, err := peer.EntitiesFromResult(res).ExtractPeer(thread.PeerID)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "extract discussion group")
+	}
+	return group, thread.ID, nil
+}
+
+// printComments prints comments of given channel post.
+func printComments(ctx context.Context, api *tg.Client, channel tg.InputPeerClass, postID int) error {
+	iter := query.Messages(api).GetReplies(channel).MsgID(postID).Iter()
+	count := 0
+	for iter.Next(ctx) {
+		elem := iter.Value()
+		msg, ok := elem.Msg.(*tg.Message)
+		if !ok {
+			continue
+		}
+		from := "anonymous"
+		if u, ok := msg.FromID.(*tg.PeerUser); ok {
+			from = fmt.Sprintf("user %d", u.UserID)
+			if user, ok := elem.Entities.User(u.UserID); ok {
+				from = user.FirstName
+				if user.Username != "" {
+					from += " (@" + user.Username + ")"
+				}
+			}
+		}
+		date := time.Unix(int64(msg.Date), 0).Format(time.DateTime)
+		fmt.Printf("[%d] %s %s: %s\n", msg.ID, date, from, msg.Message)
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "iterate comments")
+	}
+	fmt.Println("Total comments:", count)
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram/dcs"
+	"github.com/gotd/td/transport"
+	"go.uber.org/zap"
+)
+
+// connStateResolver wraps resolver to log connection state transitions
+// of primary DC connections.
+//
+// Client has no connection callbacks, so connections are observed from
+// dial and first failed read or close.
+type connStateResolver struct {
+	dcs.Resolver
+	lg     *zap.Logger
+	prefix string
+
+	mux sync.Mutex
+	// lost is number of lost connections by DC, to tell reconnects from
+	// initial connects and connections to other DCs.
+	lost map[int]int
+}
+
+// Primary implements dcs.Resolver.
+func (r *connStateResolver) Primary(ctx context.Context, dc int, list dcs.List) (transport.Conn, error) {
+	conn, err := r.Resolver.Primary(ctx, dc, list)
+	if err != nil {
+		getMetrics().Connection(dc, "failed")
+		r.lg.Warn("Connection failed", zap.Int("dc", dc), zap.Error(err))
+		return nil, err
+	}
+	r.mux.Lock()
+	reconnect := r.lost[dc] > 0
+	if reconnect {
+		r.lost[dc]--
+	}
+	r.mux.Unlock()
+
+	if reconnect {
+		getMetrics().Connection(dc, "reconnected")
+		r.lg.Info("Reconnected", zap.Int("dc", dc))
+		fmt.Printf("%sReconnected to DC %d\n", r.prefix, dc)
+	} else {
+		getMetrics().Connection(dc, "connected")
+		r.lg.Info("Connected", zap.Int("dc", dc))
+	}
+	return &stateConn{Conn: conn, r: r, dc: dc}, nil
+}
+
+func (r *connStateResolver) disconnected(dc int, err error) {
+	r.mux.Lock()
+	if r.lost == nil {
+		r.lost = map[int]int{}
+	}
+	r.lost[dc]++
+	r.mux.Unlock()
+
+	getMetrics().Connection(dc, "disconnected")
+	r.lg.Info("Disconnected", zap.Int("dc", dc), zap.Error(err))
+}
+
+// stateConn logs disconnect of connection once.
+type stateConn struct {
+	transport.Conn
+	r    *connStateResolver
+	dc   int
+	once sync.Once
+}
+
+func (c *stateConn) disconnected(err error) {
+	c.once.Do(func() {
+		c.r.disconnected(c.dc, err)
+	})
+}
+
+func (c *stateConn) Recv(ctx context.Context, b *bin.Buffer) error {
+	err := c.Conn.Recv(ctx, b)
+	if err != nil && ctx.Err() == nil {
+		c.disconnected(err)
+	}
+	return err
+}
+
+func (c *stateConn) Close() error {
+	c.disconnected(nil)
+	return c.Conn.Close()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+	"go.uber.org/multierr"
+)
+
+// processedPrefix is key prefix of last processed message ID per peer in
+// pebble database.
+var processedPrefix = []byte("processed/")
+
+// dedupe tracks last processed message ID per peer, so messages recovered
+// again after crash in the middle of handling are skipped.
+type dedupe struct {
+	db *pebbledb.DB
+}
+
+func newDedupe(db *pebbledb.DB) *dedupe {
+	return &dedupe{db: db}
+}
+
+func processedKey(p tg.PeerClass) ([]byte, bool) {
+	var kind string
+	switch p.(type) {
+	case *tg.PeerUser:
+		kind = "user"
+	case *tg.PeerChat:
+		kind = "chat"
+	case *tg.PeerChannel:
+		kind = "channel"
+	default:
+		return nil, false
+	}
+	return append(append([]byte(nil), processedPrefix...), fmt.Sprintf("%s/%d", kind, peerClassID(p))...), true
+}
+
+// Seen reports whether message with given ID from peer is already
+// processed.
+func (d *dedupe) Seen(p tg.PeerClass, msgID int) (_ bool, rerr error) {
+	key, ok := processedKey(p)
+	if !ok {
+		return false, nil
+	}
+	data, closer, err := d.db.Get(key)
+	if errors.Is(err, pebbledb.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "get")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, closer.Close())
+	}()
+	if len(data) != 8 {
+		return false, errors.Errorf("invalid length %d", len(data))
+	}
+	// Message IDs are increasing in chat.
+	return msgID <= int(binary.BigEndian.Uint64(data)), nil
+}
+
+// Done marks message with given ID from peer as processed.
+func (d *dedupe) Done(p tg.PeerClass, msgID int) error {
+	key, ok := processedKey(p)
+	if !ok {
+		return nil
+	}
+	data := binary.BigEndian.AppendUint64(nil, uint64(msgID))
+	if err := d.db.Set(key, data, pebbledb.Sync); err != nil {
+		return errors.Wrap(err, "set")
+	}
+	return nil
+}
+
+// dedupeHandler wraps handler of new message updates, skipping already
+// processed messages and marking message as processed if h succeeds.
+//
+// If d is nil, h is returned as is.
+func dedupeHandler[U interface {
+	tg.UpdateClass
+	GetMessage() tg.MessageClass
+}](d *dedupe, h func(context.Context, tg.Entities, U) error) func(context.Context, tg.Entities, U) error {
+	if d == nil {
+		return h
+	}
+	return func(ctx context.Context, e tg.Entities, u U) error {
+		msg, ok := u.GetMessage().(*tg.Message)
+		if !ok {
+			return h(ctx, e, u)
+		}
+		seen, err := d.Seen(msg.PeerID, msg.ID)
+		if err != nil {
+			return errors.Wrap(err, "dedupe")
+		}
+		if seen {
+			return nil
+		}
+		if err := h(ctx, e, u); err != nil {
+			return err
+		}
+		return d.Done(msg.PeerID, msg.ID)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+
+	"github.com/gotd/td/telegram"
+)
+
+// deviceFromEnv returns device info reported to Telegram on connection.
+//
+// Unset fields are filled with gotd defaults.
+func deviceFromEnv() telegram.DeviceConfig {
+	lang := os.Getenv("TG_LANG_CODE")
+	return telegram.DeviceConfig{
+		DeviceModel:    os.Getenv("TG_DEVICE_MODEL"),
+		SystemVersion:  os.Getenv("TG_SYSTEM_VERSION"),
+		AppVersion:     os.Getenv("TG_APP_VERSION"),
+		SystemLangCode: lang,
+		LangCode:       lang,
+	}
+}
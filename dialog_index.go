@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/telegram/query/dialogs"
+	"github.com/gotd/td/tg"
+	"go.uber.org/multierr"
+)
+
+// dialogIndexPrefix is key prefix of dialog index entries in pebble database.
+var dialogIndexPrefix = []byte("dialog_index/")
+
+// dialogEntry is entry of local dialog index.
+type dialogEntry struct {
+	Kind        string    `json:"kind"`
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Username    string    `json:"username,omitempty"`
+	TopMessage  int       `json:"top_message"`
+	LastMessage string    `json:"last_message,omitempty"`
+	LastDate    int       `json:"last_date,omitempty"`
+	Unread      int       `json:"unread"`
+	Mentions    int       `json:"mentions"`
+	Archived    bool      `json:"archived"`
+	Folders     []string  `json:"folders,omitempty"`
+	Indexed     time.Time `json:"indexed"`
+}
+
+func (e dialogEntry) key() []byte {
+	return append(append([]byte(nil), dialogIndexPrefix...), fmt.Sprintf("%s/%d", e.Kind, e.ID)...)
+}
+
+// inputPeerKey returns kind and ID of given peer.
+func inputPeerKey(p tg.InputPeerClass) (string, int64, bool) {
+	switch p := p.(type) {
+	case *tg.InputPeerUser:
+		return "user", p.UserID, true
+	case *tg.InputPeerChat:
+		return "chat", p.ChatID, true
+	case *tg.InputPeerChannel:
+		return "channel", p.ChannelID, true
+	default:
+		return "", 0, false
+	}
+}
+
+// peerClassID returns ID of peer.
+func peerClassID(p tg.PeerClass) int64 {
+	switch p := p.(type) {
+	case *tg.PeerUser:
+		return p.UserID
+	case *tg.PeerChat:
+		return p.ChatID
+	case *tg.PeerChannel:
+		return p.ChannelID
+	default:
+		return 0
+	}
+}
+
+// DialogIndex is local index of dialogs stored in pebble database, used
+// for fast offline dialog search.
+type DialogIndex struct {
+	db *pebbledb.DB
+}
+
+// NewDialogIndex creates new DialogIndex.
+func NewDialogIndex(db *pebbledb.DB) *DialogIndex {
+	return &DialogIndex{db: db}
+}
+
+func (i *DialogIndex) get(key []byte) (_ dialogEntry, _ bool, rerr error) {
+	data, closer, err := i.db.Get(key)
+	if errors.Is(err, pebbledb.ErrNotFound) {
+		return dialogEntry{}, false, nil
+	}
+	if err != nil {
+		return dialogEntry{}, false, errors.Wrapf(err, "get %q", key)
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, closer.Close())
+	}()
+
+	var e dialogEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return dialogEntry{}, false, errors.Wrap(err, "unmarshal")
+	}
+	return e, true, nil
+}
+
+func (i *DialogIndex) set(e dialogEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	if err := i.db.Set(e.key(), data, nil); err != nil {
+		return errors.Wrap(err, "set")
+	}
+	return nil
+}
+
+// folders returns names of dialog folders (filters) by explicitly included
+// peers.
+func (i *DialogIndex) folders(ctx context.Context, api *tg.Client) (map[string][]string, error) {
+	filters, err := api.MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get dialog filters")
+	}
+	r := map[string][]string{}
+	for _, f := range filters {
+		f, ok := f.(*tg.DialogFilter)
+		if !ok {
+			continue
+		}
+		// Rule-based membership (contacts, groups, etc.) is not
+		// resolved, only explicitly added peers.
+		for _, p := range append(f.PinnedPeers, f.IncludePeers...) {
+			kind, id, ok := inputPeerKey(p)
+			if !ok {
+				continue
+			}
+			k := fmt.Sprintf("%s/%d", kind, id)
+			r[k] = append(r[k], f.Title)
+		}
+	}
+	return r, nil
+}
+
+// changed reports whether entry differs from previously indexed one.
+func (e dialogEntry) changed(prev dialogEntry) bool {
+	prev.Indexed = e.Indexed
+	return !reflect.DeepEqual(e, prev)
+}
+
+// Build fetches all dialogs and updates index.
+//
+// Unread counters of old dialogs change when they are read on another
+// device, so all dialogs are fetched, but only changed entries are written
+// unless full is true. Entries of dialogs that are no longer in the list
+// are removed.
+func (i *DialogIndex) Build(ctx context.Context, api *tg.Client, full bool) error {
+	folders, err := i.folders(ctx, api)
+	if err != nil {
+		return err
+	}
+
+	var updated, total int
+	seen := map[string]struct{}{}
+	// Main list and archive.
+	for _, folderID := range []int{0, 1} {
+		iter := query.GetDialogs(api).FolderID(folderID).Iter()
+		for iter.Next(ctx) {
+			elem := iter.Value()
+			e, ok := newDialogEntry(elem)
+			if !ok {
+				continue
+			}
+			e.Archived = folderID == 1
+			e.Folders = folders[fmt.Sprintf("%s/%d", e.Kind, e.ID)]
+			total++
+			seen[string(e.key())] = struct{}{}
+
+			prev, found, err := i.get(e.key())
+			if err != nil {
+				return errors.Wrap(err, "get entry")
+			}
+			if found && !full && !e.changed(prev) {
+				continue
+			}
+			if err := i.set(e); err != nil {
+				return errors.Wrap(err, "set entry")
+			}
+			updated++
+		}
+		if err := iter.Err(); err != nil {
+			return errors.Wrap(err, "iterate dialogs")
+		}
+	}
+	removed, err := i.removeExcept(seen)
+	if err != nil {
+		return errors.Wrap(err, "remove stale entries")
+	}
+	fmt.Printf("Indexed %d dialogs (%d updated, %d removed)\n", total, updated, removed)
+	return nil
+}
+
+// removeExcept removes index entries with keys not in keep, i.e. of
+// deleted or left dialogs.
+func (i *DialogIndex) removeExcept(keep map[string]struct{}) (_ int, rerr error) {
+	iter := i.db.NewIter(&pebbledb.IterOptions{
+		LowerBound: dialogIndexPrefix,
+		UpperBound: append(append([]byte(nil), dialogIndexPrefix...), 0xff),
+	})
+	defer func() {
+		multierr.AppendInto(&rerr, iter.Close())
+	}()
+
+	b := i.db.NewBatch()
+	defer func() {
+		multierr.AppendInto(&rerr, b.Close())
+	}()
+	removed := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		if _, ok := keep[string(iter.Key())]; ok {
+			continue
+		}
+		if err := b.Delete(iter.Key(), nil); err != nil {
+			return 0, errors.Wrap(err, "delete")
+		}
+		removed++
+	}
+	if err := iter.Error(); err != nil {
+		return 0, errors.Wrap(err, "iter")
+	}
+	if err := b.Commit(nil); err != nil {
+		return 0, errors.Wrap(err, "commit")
+	}
+	return removed, nil
+}
+
+func newDialogEntry(elem dialogs.Elem) (dialogEntry, bool) {
+	kind, id, ok := inputPeerKey(elem.Peer)
+	if !ok {
+		return dialogEntry{}, false
+	}
+	e := dialogEntry{
+		Kind:    kind,
+		ID:      id,
+		Indexed: time.Now(),
+	}
+	switch kind {
+	case "user":
+		if u, ok := elem.Entities.User(id); ok {
+			e.Title = strings.TrimSpace(u.FirstName + " " + u.LastName)
+			e.Username = u.Username
+		}
+	case "chat":
+		if c, ok := elem.Entities.Chat(id); ok {
+			e.Title = c.Title
+		}
+	case "channel":
+		if c, ok := elem.Entities.Channel(id); ok {
+			e.Title = c.Title
+			e.Username = c.Username
+		}
+	}
+	if d, ok := elem.Dialog.(*tg.Dialog); ok {
+		e.TopMessage = d.TopMessage
+		e.Unread = d.UnreadCount
+		e.Mentions = d.UnreadMentionsCount
+	}
+	if msg, ok := elem.Last.(*tg.Message); ok {
+		e.LastMessage = msg.Message
+		e.LastDate = msg.Date
+	}
+	return e, true
+}
+
+// Search returns index entries with title or username containing query,
+// case-insensitive.
+func (i *DialogIndex) Search(q string) (_ []dialogEntry, rerr error) {
+	q = strings.ToLower(strings.TrimPrefix(q, "@"))
+	upper := append(append([]byte(nil), dialogIndexPrefix...), 0xff)
+
+	iter := i.db.NewIter(&pebbledb.IterOptions{
+		LowerBound: dialogIndexPrefix,
+		UpperBound: upper,
+	})
+	defer func() {
+		multierr.AppendInto(&rerr, iter.Close())
+	}()
+
+	var r []dialogEntry
+	for iter.First(); iter.Valid(); iter.Next() {
+		var e dialogEntry
+		if err := json.Unmarshal(iter.Value(), &e); err != nil {
+			return nil, errors.Wrap(err, "unmarshal")
+		}
+		if strings.Contains(strings.ToLower(e.Title), q) ||
+			strings.Contains(strings.ToLower(e.Username), q) {
+			r = append(r, e)
+		}
+	}
+	return r, nil
+}
+
+// printDialogSearch prints dialogs from local index matching query.
+func printDialogSearch(index *DialogIndex, q string) error {
+	entries, err := index.Search(q)
+	if err != nil {
+		return errors.Wrap(err, "search")
+	}
+	if len(entries) == 0 {
+		fmt.Println("Nothing found (try -build-index)")
+		return nil
+	}
+	for _, e := range entries {
+		name := e.Title
+		if e.Username != "" {
+			name += " (@" + e.Username + ")"
+		}
+		fmt.Printf("[%s %d] %s\n", e.Kind, e.ID, name)
+		if e.Unread > 0 || e.Mentions > 0 {
+			fmt.Printf("  unread: %d, mentions: %d\n", e.Unread, e.Mentions)
+		}
+		if e.Archived {
+			fmt.Println("  archived")
+		}
+		if len(e.Folders) > 0 {
+			fmt.Println("  folders:", strings.Join(e.Folders, ", "))
+		}
+		if e.LastDate != 0 {
+			date := time.Unix(int64(e.LastDate), 0).Format(time.DateTime)
+			fmt.Printf("  %s: %s\n", date, e.LastMessage)
+		}
+	}
+	return nil
+}
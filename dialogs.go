@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+)
+
+// printDialogs prints table of all dialogs with unread counts, most
+// recently active first.
+//
+// Peers of dialogs are stored to peer storage, so they can be referenced
+// by ID later.
+func printDialogs(ctx context.Context, api *tg.Client, peerDB storage.PeerStorage) error {
+	var entries []dialogEntry
+	iter := query.GetDialogs(api).Iter()
+	for iter.Next(ctx) {
+		elem := iter.Value()
+		e, ok := newDialogEntry(elem)
+		if !ok {
+			continue
+		}
+		entries = append(entries, e)
+
+		var (
+			p     storage.Peer
+			found bool
+		)
+		switch e.Kind {
+		case "user":
+			if u, ok := elem.Entities.User(e.ID); ok {
+				found = p.FromUser(u)
+			}
+		case "chat":
+			if c, ok := elem.Entities.Chat(e.ID); ok {
+				found = p.FromChat(c)
+			}
+		case "channel":
+			if c, ok := elem.Entities.Channel(e.ID); ok {
+				found = p.FromChat(c)
+			}
+		}
+		if !found {
+			continue
+		}
+		if err := peerDB.Add(ctx, p); err != nil {
+			return errors.Wrap(err, "add peer")
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "iterate dialogs")
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastDate > entries[j].LastDate
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID\tTYPE\tUNREAD\tLAST")
+	var unread int
+	for _, e := range entries {
+		name := e.Title
+		if e.Username != "" {
+			name += " (@" + e.Username + ")"
+		}
+		var last string
+		if e.LastDate != 0 {
+			last = time.Unix(int64(e.LastDate), 0).Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\n", name, e.ID, e.Kind, e.Unread, last)
+		unread += e.Unread
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "flush")
+	}
+	fmt.Printf("%d dialogs, %d unread messages\n", len(entries), unread)
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// downloadAll exports full history of dialog with given peer to dir:
+// messages are appended to messages.jsonl and media is downloaded to
+// media subdirectory.
+//
+// History is fetched from newest to oldest message and ID of last
+// fetched message is saved to checkpoint file, so interrupted download
+// is resumed from it.
+func downloadAll(ctx context.Context, lg *zap.Logger, api *tg.Client, cache *mediaCache, p tg.InputPeerClass, dir string) (rerr error) {
+	media := newMediaDownloader(api, lg, filepath.Join(dir, "media"), cache)
+	if err := os.MkdirAll(media.dir, 0700); err != nil {
+		return errors.Wrap(err, "create dir")
+	}
+
+	checkpoint := filepath.Join(dir, "checkpoint")
+	var offsetID int
+	switch data, err := os.ReadFile(checkpoint); {
+	case os.IsNotExist(err):
+	case err != nil:
+		return errors.Wrap(err, "read checkpoint")
+	default:
+		if offsetID, err = strconv.Atoi(strings.TrimSpace(string(data))); err != nil {
+			return errors.Wrap(err, "parse checkpoint")
+		}
+		if offsetID <= 1 {
+			fmt.Println("History is already downloaded to", dir)
+			return nil
+		}
+		fmt.Println("Resuming from message", offsetID)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "messages.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "open messages")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, f.Close())
+	}()
+	e := json.NewEncoder(f)
+
+	var count, files int
+	iter := query.Messages(api).GetHistory(p).OffsetID(offsetID).BatchSize(100).Iter()
+	for iter.Next(ctx) {
+		msg, ok := iter.Value().Msg.(*tg.Message)
+		if !ok {
+			continue
+		}
+		if loc, name, key, ok := mediaFile(msg); ok {
+			path := filepath.Join(media.dir, fmt.Sprintf("%d_%s", msg.ID, name))
+			if _, err := os.Lstat(path); err != nil {
+				if err := media.save(ctx, loc, key, path); err != nil {
+					return errors.Wrapf(err, "download media of %d", msg.ID)
+				}
+				files++
+			}
+		}
+		if err := e.Encode(exportedMessage{
+			ID:    msg.ID,
+			Date:  msg.Date,
+			Text:  msg.Message,
+			Media: mediaRef(msg.Media),
+		}); err != nil {
+			return errors.Wrap(err, "write")
+		}
+		// Message is written before checkpoint, so it can be duplicated
+		// on resume, but never lost.
+		if err := os.WriteFile(checkpoint, []byte(strconv.Itoa(msg.ID)), 0600); err != nil {
+			return errors.Wrap(err, "write checkpoint")
+		}
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "iterate history")
+	}
+	// Marking history as fully downloaded: there are no messages older
+	// than first one.
+	if err := os.WriteFile(checkpoint, []byte("1"), 0600); err != nil {
+		return errors.Wrap(err, "write checkpoint")
+	}
+	fmt.Printf("Downloaded %d messages and %d files to %s\n", count, files, dir)
+	return nil
+}
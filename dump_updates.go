@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// dumpedUpdate is JSON line of raw update.
+type dumpedUpdate struct {
+	Time time.Time `json:"time"`
+	// Type is TL type of update.
+	Type string `json:"type"`
+	// Update is human-readable update, nested interfaces are encoded
+	// without type names, so it is not enough to restore update.
+	Update tg.UpdatesClass `json:"update"`
+	// Raw is base64-encoded TL serialization of update, which can be
+	// decoded with tg.DecodeUpdates.
+	Raw string `json:"raw"`
+}
+
+// updateDumper writes every update as JSON line before passing it to next
+// handler.
+type updateDumper struct {
+	next telegram.UpdateHandler
+
+	mux sync.Mutex
+	e   *json.Encoder
+}
+
+func newUpdateDumper(next telegram.UpdateHandler, w io.Writer) *updateDumper {
+	return &updateDumper{next: next, e: json.NewEncoder(w)}
+}
+
+// Handle implements telegram.UpdateHandler.
+func (d *updateDumper) Handle(ctx context.Context, u tg.UpdatesClass) error {
+	var b bin.Buffer
+	if err := u.Encode(&b); err != nil {
+		return errors.Wrap(err, "encode update")
+	}
+	d.mux.Lock()
+	err := d.e.Encode(dumpedUpdate{
+		Time:   time.Now(),
+		Type:   u.TypeName(),
+		Update: u,
+		Raw:    base64.StdEncoding.EncodeToString(b.Raw()),
+	})
+	d.mux.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "dump update")
+	}
+	return d.next.Handle(ctx, u)
+}
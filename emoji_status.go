@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// setEmojiStatus sets emoji status of current user to custom emoji with
+// given document ID. Zero ID clears status, non-zero duration makes status
+// temporary.
+//
+// Emoji status can be set only by Telegram Premium users, but cleared
+// by anyone, e.g. after premium has lapsed.
+func setEmojiStatus(ctx context.Context, api *tg.Client, self *tg.User, documentID int64, duration time.Duration) error {
+	if documentID != 0 && !self.Premium {
+		return errors.New("emoji status requires Telegram Premium")
+	}
+	if duration < 0 {
+		return errors.New("negative duration")
+	}
+
+	var status tg.EmojiStatusClass
+	switch {
+	case documentID == 0:
+		status = &tg.EmojiStatusEmpty{}
+	case duration > 0:
+		status = &tg.EmojiStatusUntil{
+			DocumentID: documentID,
+			Until:      int(time.Now().Add(duration).Unix()),
+		}
+	default:
+		status = &tg.EmojiStatus{DocumentID: documentID}
+	}
+
+	if _, err := api.AccountUpdateEmojiStatus(ctx, status); err != nil {
+		if tgerr.Is(err, "PREMIUM_ACCOUNT_REQUIRED") {
+			return errors.New("emoji status requires Telegram Premium")
+		}
+		return errors.Wrap(err, "update emoji status")
+	}
+	if documentID == 0 {
+		fmt.Println("Emoji status cleared")
+	} else {
+		fmt.Println("Emoji status set")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/joho/godotenv"
+)
+
+// stringsFlag is repeatable string flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// loadEnvFiles loads environment variables from given env files, later
+// files overriding earlier ones, or from ".env" if no files are given.
+//
+// Like godotenv.Load, variables already set in process environment are
+// not overridden. Missing default ".env" is ignored.
+func loadEnvFiles(files []string) error {
+	if len(files) == 0 {
+		if _, err := os.Stat(".env"); os.IsNotExist(err) {
+			return nil
+		}
+		files = []string{".env"}
+	}
+	env := map[string]string{}
+	for _, name := range files {
+		vars, err := godotenv.Read(name)
+		if err != nil {
+			return errors.Wrapf(err, "read %s", name)
+		}
+		for k, v := range vars {
+			env[k] = v
+		}
+	}
+	for k, v := range env {
+		if _, ok := os.LookupEnv(k); ok {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return errors.Wrapf(err, "set %s", k)
+		}
+	}
+	return nil
+}
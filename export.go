@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+	"go.uber.org/multierr"
+)
+
+// exportedMessage is single exported message.
+type exportedMessage struct {
+	ID   int    `json:"id"`
+	Date int    `json:"date"`
+	Text string `json:"text"`
+	// Media is reference to media, see mediaRef. Details are in MediaInfo.
+	Media     string           `json:"media,omitempty"`
+	Entities  []exportedEntity `json:"entities,omitempty"`
+	MediaInfo *exportedMedia   `json:"media_info,omitempty"`
+	// Hash is SHA256 of other fields, set if checksums are enabled.
+	Hash string `json:"hash,omitempty"`
+}
+
+// exportedEntity is formatting entity of exported message text.
+type exportedEntity struct {
+	// Type is entity type like "bold" or "textUrl".
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	URL    string `json:"url,omitempty"`
+	UserID int64  `json:"user_id,omitempty"`
+}
+
+// exportedMedia is media of exported message.
+type exportedMedia struct {
+	// Type is "photo", "document" or TL type name of other media.
+	Type     string `json:"type"`
+	ID       int64  `json:"id,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	FileName string `json:"file_name,omitempty"`
+}
+
+// exportManifest is checksum manifest of export file.
+type exportManifest struct {
+	Format  string `json:"format,omitempty"`
+	Count   int    `json:"count"`
+	FirstID int    `json:"first_id"`
+	LastID  int    `json:"last_id"`
+	SHA256  string `json:"sha256"`
+}
+
+// manifestPath returns path of manifest of export file.
+func manifestPath(path string) string {
+	return path + ".manifest.json"
+}
+
+// mediaRef returns reference to media of message, like "photo:123".
+func mediaRef(media tg.MessageMediaClass) string {
+	switch m := media.(type) {
+	case nil:
+		return ""
+	case *tg.MessageMediaPhoto:
+		if photo, ok := m.Photo.(*tg.Photo); ok {
+			return "photo:" + strconv.FormatInt(photo.ID, 10)
+		}
+	case *tg.MessageMediaDocument:
+		if doc, ok := m.Document.(*tg.Document); ok {
+			return "document:" + strconv.FormatInt(doc.ID, 10)
+		}
+	}
+	return media.TypeName()
+}
+
+// exportEntities returns formatting entities of message.
+func exportEntities(entities []tg.MessageEntityClass) []exportedEntity {
+	var r []exportedEntity
+	for _, e := range entities {
+		typ := strings.TrimPrefix(e.TypeName(), "messageEntity")
+		v := exportedEntity{
+			Type:   strings.ToLower(typ[:1]) + typ[1:],
+			Offset: e.GetOffset(),
+			Length: e.GetLength(),
+		}
+		switch e := e.(type) {
+		case *tg.MessageEntityTextURL:
+			v.URL = e.URL
+		case *tg.MessageEntityMentionName:
+			v.UserID = e.UserID
+		}
+		r = append(r, v)
+	}
+	return r
+}
+
+// exportMedia returns media of message, if any.
+func exportMedia(media tg.MessageMediaClass) *exportedMedia {
+	switch m := media.(type) {
+	case nil:
+		return nil
+	case *tg.MessageMediaPhoto:
+		if photo, ok := m.Photo.(*tg.Photo); ok {
+			return &exportedMedia{Type: "photo", ID: photo.ID}
+		}
+	case *tg.MessageMediaDocument:
+		if doc, ok := m.Document.(*tg.Document); ok {
+			v := &exportedMedia{Type: "document", ID: doc.ID, MIMEType: doc.MimeType, Size: doc.Size}
+			for _, attr := range doc.Attributes {
+				if attr, ok := attr.(*tg.DocumentAttributeFilename); ok {
+					v.FileName = attr.FileName
+				}
+			}
+			return v
+		}
+	}
+	return &exportedMedia{Type: media.TypeName()}
+}
+
+// hash computes checksum of message fields.
+func (m exportedMessage) hash() string {
+	h := sha256.New()
+	// Length-prefixing strings, so fields can't be shifted between each other.
+	_, _ = fmt.Fprintf(h, "%d\n%d\n%d:%s\n%d:%s", m.ID, m.Date, len(m.Text), m.Text, len(m.Media), m.Media)
+	if len(m.Entities) > 0 || m.MediaInfo != nil {
+		// Nested fields were added later, so checksums of older exports
+		// without them are still valid.
+		nested, _ := json.Marshal(struct {
+			Entities  []exportedEntity `json:"entities,omitempty"`
+			MediaInfo *exportedMedia   `json:"media_info,omitempty"`
+		}{m.Entities, m.MediaInfo})
+		_, _ = fmt.Fprintf(h, "\n%d:%s", len(nested), nested)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// exportHistory writes history of dialog with given peer to file in given
+// format, optionally with per-message checksums and manifest.
+func exportHistory(ctx context.Context, api *tg.Client, p tg.InputPeerClass, path, format string, checksums bool) (rerr error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "create")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, f.Close())
+	}()
+
+	fileHash := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(f, fileHash))
+	sink, err := newExportSink(format, w)
+	if err != nil {
+		return err
+	}
+
+	manifest := exportManifest{Format: format}
+	iter := query.Messages(api).GetHistory(p).BatchSize(100).Iter()
+	for iter.Next(ctx) {
+		msg, ok := iter.Value().Msg.(*tg.Message)
+		if !ok {
+			continue
+		}
+		m := exportedMessage{
+			ID:        msg.ID,
+			Date:      msg.Date,
+			Text:      msg.Message,
+			Media:     mediaRef(msg.Media),
+			Entities:  exportEntities(msg.Entities),
+			MediaInfo: exportMedia(msg.Media),
+		}
+		if checksums {
+			m.Hash = m.hash()
+		}
+		if err := sink.Write(m); err != nil {
+			return errors.Wrap(err, "write")
+		}
+		// History is iterated from newest to oldest message.
+		if manifest.Count == 0 {
+			manifest.LastID = m.ID
+		}
+		manifest.FirstID = m.ID
+		manifest.Count++
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "iterate history")
+	}
+	if err := sink.Flush(); err != nil {
+		return errors.Wrap(err, "flush")
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "flush")
+	}
+	fmt.Printf("Exported %d messages to %s\n", manifest.Count, path)
+	if !checksums {
+		return nil
+	}
+
+	manifest.SHA256 = hex.EncodeToString(fileHash.Sum(nil))
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal manifest")
+	}
+	if err := os.WriteFile(manifestPath(path), data, 0600); err != nil {
+		return errors.Wrap(err, "write manifest")
+	}
+	return nil
+}
+
+// verifyExport checks export file against its manifest and per-message
+// checksums, reporting gaps in message IDs.
+//
+// Gaps are only reported, because messages can be deleted from history.
+func verifyExport(path string) (rerr error) {
+	data, err := os.ReadFile(manifestPath(path))
+	if err != nil {
+		return errors.Wrap(err, "read manifest")
+	}
+	var manifest exportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return errors.Wrap(err, "unmarshal manifest")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "open")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, f.Close())
+	}()
+
+	fileHash := sha256.New()
+	src, err := newExportSource(manifest.Format, bufio.NewReader(io.TeeReader(f, fileHash)))
+	if err != nil {
+		return err
+	}
+
+	var (
+		ids        []int
+		mismatched int
+	)
+	for n := 1; ; n++ {
+		m, err := src.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "message %d", n)
+		}
+		if m.Hash != m.hash() {
+			fmt.Printf("Checksum mismatch: message %d\n", m.ID)
+			mismatched++
+		}
+		ids = append(ids, m.ID)
+	}
+
+	sort.Ints(ids)
+	for i := 1; i < len(ids); i++ {
+		if ids[i] == ids[i-1] {
+			fmt.Printf("Duplicate: message %d\n", ids[i])
+			mismatched++
+		}
+		if ids[i]-ids[i-1] > 1 {
+			fmt.Printf("Gap: messages %d-%d missing\n", ids[i-1]+1, ids[i]-1)
+		}
+	}
+	if len(ids) != manifest.Count {
+		fmt.Printf("Count mismatch: %d messages, manifest has %d\n", len(ids), manifest.Count)
+		mismatched++
+	}
+	if len(ids) > 0 && (ids[0] != manifest.FirstID || ids[len(ids)-1] != manifest.LastID) {
+		fmt.Printf("Range mismatch: %d-%d, manifest has %d-%d\n",
+			ids[0], ids[len(ids)-1], manifest.FirstID, manifest.LastID,
+		)
+		mismatched++
+	}
+	if sum := hex.EncodeToString(fileHash.Sum(nil)); sum != manifest.SHA256 {
+		fmt.Printf("File checksum mismatch: %s, manifest has %s\n", sum, manifest.SHA256)
+		mismatched++
+	}
+
+	fmt.Printf("Checked %d messages\n", len(ids))
+	if mismatched > 0 {
+		return errors.Errorf("export is corrupted: %d problems found", mismatched)
+	}
+	fmt.Println("Export is intact")
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/go-faster/errors"
+)
+
+// Export formats.
+const (
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+)
+
+// csvHeader is header of CSV export. New columns should be appended, so
+// readers relying on column order are not broken.
+var csvHeader = []string{"id", "date", "text", "media", "hash", "entities", "media_info"}
+
+// exportSink writes exported messages in some format.
+type exportSink interface {
+	Write(m exportedMessage) error
+	// Flush writes buffered data, if any.
+	Flush() error
+}
+
+// exportSource reads exported messages in some format.
+type exportSource interface {
+	// Read returns next message or io.EOF.
+	Read() (exportedMessage, error)
+}
+
+// newExportSink creates sink writing given format to w.
+func newExportSink(format string, w io.Writer) (exportSink, error) {
+	switch format {
+	case formatJSONL:
+		return jsonlSink{e: json.NewEncoder(w)}, nil
+	case formatCSV:
+		s := csvSink{w: csv.NewWriter(w)}
+		if err := s.w.Write(csvHeader); err != nil {
+			return nil, errors.Wrap(err, "write header")
+		}
+		return s, nil
+	default:
+		return nil, errors.Errorf("unknown format %q, expected %s or %s", format, formatJSONL, formatCSV)
+	}
+}
+
+// newExportSource creates source reading given format from r.
+func newExportSource(format string, r io.Reader) (exportSource, error) {
+	switch format {
+	case formatJSONL, "":
+		// Manifests of exports made before formats were added have no
+		// format, which means JSONL.
+		return jsonlSource{d: json.NewDecoder(r)}, nil
+	case formatCSV:
+		s := &csvSource{r: csv.NewReader(r)}
+		if err := s.readHeader(); err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, errors.Errorf("unknown format %q", format)
+	}
+}
+
+type jsonlSink struct {
+	e *json.Encoder
+}
+
+func (s jsonlSink) Write(m exportedMessage) error { return s.e.Encode(m) }
+
+func (s jsonlSink) Flush() error { return nil }
+
+type jsonlSource struct {
+	d *json.Decoder
+}
+
+func (s jsonlSource) Read() (m exportedMessage, err error) {
+	err = s.d.Decode(&m)
+	return m, err
+}
+
+// csvSink writes messages as CSV, nested fields are JSON-encoded.
+type csvSink struct {
+	w *csv.Writer
+}
+
+func (s csvSink) Write(m exportedMessage) error {
+	var entities, mediaInfo []byte
+	if len(m.Entities) > 0 {
+		data, err := json.Marshal(m.Entities)
+		if err != nil {
+			return errors.Wrap(err, "marshal entities")
+		}
+		entities = data
+	}
+	if m.MediaInfo != nil {
+		data, err := json.Marshal(m.MediaInfo)
+		if err != nil {
+			return errors.Wrap(err, "marshal media info")
+		}
+		mediaInfo = data
+	}
+	return s.w.Write([]string{
+		strconv.Itoa(m.ID),
+		strconv.Itoa(m.Date),
+		m.Text,
+		m.Media,
+		m.Hash,
+		string(entities),
+		string(mediaInfo),
+	})
+}
+
+func (s csvSink) Flush() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+type csvSource struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func (s *csvSource) readHeader() error {
+	header, err := s.r.Read()
+	if err != nil {
+		return errors.Wrap(err, "read header")
+	}
+	s.columns = map[string]int{}
+	for i, name := range header {
+		s.columns[name] = i
+	}
+	// Columns are looked up by name, so any order and unknown columns
+	// are accepted.
+	s.r.FieldsPerRecord = len(header)
+	return nil
+}
+
+func (s *csvSource) Read() (exportedMessage, error) {
+	record, err := s.r.Read()
+	if err != nil {
+		return exportedMessage{}, err
+	}
+	get := func(name string) string {
+		if i, ok := s.columns[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+	var m exportedMessage
+	if m.ID, err = strconv.Atoi(get("id")); err != nil {
+		return m, errors.Wrap(err, "id")
+	}
+	if m.Date, err = strconv.Atoi(get("date")); err != nil {
+		return m, errors.Wrap(err, "date")
+	}
+	m.Text = get("text")
+	m.Media = get("media")
+	m.Hash = get("hash")
+	if v := get("entities"); v != "" {
+		if err := json.Unmarshal([]byte(v), &m.Entities); err != nil {
+			return m, errors.Wrap(err, "entities")
+		}
+	}
+	if v := get("media_info"); v != "" {
+		m.MediaInfo = new(exportedMedia)
+		if err := json.Unmarshal([]byte(v), m.MediaInfo); err != nil {
+			return m, errors.Wrap(err, "media info")
+		}
+	}
+	return m, nil
+}
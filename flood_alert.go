@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"go.uber.org/zap"
+)
+
+// floodAlertTimeout is timeout of alert webhook call.
+const floodAlertTimeout = 5 * time.Second
+
+// floodAlertEvent is payload of flood wait alert webhook.
+type floodAlertEvent struct {
+	Event     string    `json:"event"`
+	Method    string    `json:"method"`
+	Wait      float64   `json:"wait_seconds"`
+	Threshold float64   `json:"threshold_seconds"`
+	Session   string    `json:"session"`
+	Time      time.Time `json:"time"`
+}
+
+// floodAlert reports flood waits longer than threshold.
+type floodAlert struct {
+	lg        *zap.Logger
+	threshold time.Duration
+	webhook   string
+	session   string
+	client    *http.Client
+}
+
+// newFloodAlert creates floodAlert, webhook is called via given transport
+// or default one if nil.
+func newFloodAlert(lg *zap.Logger, threshold time.Duration, webhook, session string, transport http.RoundTripper) *floodAlert {
+	return &floodAlert{
+		lg:        lg,
+		threshold: threshold,
+		webhook:   webhook,
+		session:   session,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   floodAlertTimeout,
+		},
+	}
+}
+
+// Middleware returns middleware that alerts on long flood waits.
+//
+// Should be placed after flood waiter to see FLOOD_WAIT errors before
+// they are retried.
+func (a *floodAlert) Middleware() telegram.Middleware {
+	return telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+		return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+			err := next.Invoke(ctx, input, output)
+			if d, ok := tgerr.AsFloodWait(err); ok && d > a.threshold {
+				a.alert(methodName(input), d)
+			}
+			return err
+		}
+	})
+}
+
+func (a *floodAlert) alert(method string, d time.Duration) {
+	e := floodAlertEvent{
+		Event:     "flood_wait",
+		Method:    method,
+		Wait:      d.Seconds(),
+		Threshold: a.threshold.Seconds(),
+		Session:   a.session,
+		Time:      time.Now(),
+	}
+	a.lg.Warn("Flood wait exceeds threshold",
+		zap.String("event", e.Event),
+		zap.String("method", method),
+		zap.Duration("wait", d),
+		zap.Duration("threshold", a.threshold),
+	)
+	if a.webhook == "" {
+		return
+	}
+	// Not blocking the waiter.
+	go func() {
+		if err := a.send(e); err != nil {
+			a.lg.Warn("Failed to send flood alert", zap.Error(err))
+		}
+	}()
+}
+
+func (a *floodAlert) send(e floodAlertEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), floodAlertTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.webhook, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := a.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "post")
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode >= 300 {
+		return errors.Errorf("webhook returned %s", res.Status)
+	}
+	return nil
+}
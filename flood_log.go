@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"go.uber.org/zap"
+)
+
+// floodLogEntry is JSON line of flood wait log.
+type floodLogEntry struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Wait   float64   `json:"wait_seconds"`
+}
+
+// floodLog is append-only log of flood waits, used to find methods that
+// trigger rate limits.
+type floodLog struct {
+	lg *zap.Logger
+
+	mux sync.Mutex
+	f   *os.File
+	e   *json.Encoder
+}
+
+func openFloodLog(lg *zap.Logger, path string) (*floodLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "open")
+	}
+	return &floodLog{lg: lg, f: f, e: json.NewEncoder(f)}, nil
+}
+
+// Middleware returns middleware that records flood waits.
+//
+// Should be placed after flood waiter to see FLOOD_WAIT errors before
+// they are retried.
+func (l *floodLog) Middleware() telegram.Middleware {
+	return telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+		return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+			err := next.Invoke(ctx, input, output)
+			if d, ok := tgerr.AsFloodWait(err); ok {
+				l.write(floodLogEntry{
+					Time:   time.Now(),
+					Method: methodName(input),
+					Wait:   d.Seconds(),
+				})
+			}
+			return err
+		}
+	})
+}
+
+func (l *floodLog) write(e floodLogEntry) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if err := l.e.Encode(e); err != nil {
+		// Log is diagnostic, so request is not failed.
+		l.lg.Warn("Failed to write flood log", zap.Error(err))
+	}
+}
+
+// Close closes log file.
+func (l *floodLog) Close() error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.f.Close()
+}
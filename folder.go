@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// folderFilter matches peers that belong to chat folder (dialog filter)
+// with given name.
+type folderFilter struct {
+	name string
+
+	mux     sync.RWMutex
+	filter  *tg.DialogFilter
+	include peerSet
+	exclude peerSet
+}
+
+func newFolderFilter(name string) *folderFilter {
+	return &folderFilter{name: name}
+}
+
+func inputPeerSet(peers []tg.InputPeerClass) peerSet {
+	r := peerSet{}
+	for _, p := range peers {
+		if _, id, ok := inputPeerKey(p); ok {
+			r[strconv.FormatInt(id, 10)] = struct{}{}
+		}
+	}
+	return r
+}
+
+// Refresh fetches current folder membership.
+func (f *folderFilter) Refresh(ctx context.Context, api *tg.Client) error {
+	filters, err := api.MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get dialog filters")
+	}
+	for _, v := range filters {
+		filter, ok := v.(*tg.DialogFilter)
+		if !ok || !strings.EqualFold(filter.Title, f.name) {
+			continue
+		}
+		include := inputPeerSet(filter.IncludePeers)
+		for k := range inputPeerSet(filter.PinnedPeers) {
+			include[k] = struct{}{}
+		}
+
+		f.mux.Lock()
+		defer f.mux.Unlock()
+		f.filter = filter
+		f.include = include
+		f.exclude = inputPeerSet(filter.ExcludePeers)
+		return nil
+	}
+	return errors.Errorf("folder %q not found", f.name)
+}
+
+// Match reports whether peer belongs to folder.
+//
+// Rules that depend on dialog state (exclude muted, read or archived) are
+// not applied.
+func (f *folderFilter) Match(p storage.Peer) bool {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	if f.filter == nil {
+		return false
+	}
+	if f.exclude.Contains(p) {
+		return false
+	}
+	if f.include.Contains(p) {
+		return true
+	}
+
+	switch {
+	case p.User != nil:
+		u := p.User
+		if u.Bot {
+			return f.filter.Bots
+		}
+		if u.Contact {
+			return f.filter.Contacts
+		}
+		return f.filter.NonContacts
+	case p.Chat != nil:
+		return f.filter.Groups
+	case p.Channel != nil:
+		if p.Channel.Broadcast {
+			return f.filter.Broadcasts
+		}
+		return f.filter.Groups
+	default:
+		return false
+	}
+}
+
+// Run refreshes folder membership with given interval.
+func (f *folderFilter) Run(ctx context.Context, lg *zap.Logger, api *tg.Client, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := f.Refresh(ctx, api); err != nil {
+				// Keeping previous membership.
+				lg.Warn("Refresh folder", zap.String("folder", f.name), zap.Error(err))
+			}
+		}
+	}
+}
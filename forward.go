@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// cacheForwardSource stores source peer of forwarded message in peer
+// storage, so it can be used later, e.g. resolved by ID.
+//
+// Source is taken from update entities or, if missing (short updates, min
+// constructors), fetched via message it was seen in.
+func cacheForwardSource(ctx context.Context, lg *zap.Logger, api *tg.Client, peerDB storage.PeerStorage, e tg.Entities, chat tg.InputPeerClass, msg *tg.Message) {
+	fwd, ok := msg.GetFwdFrom()
+	if !ok {
+		return
+	}
+	from, ok := fwd.GetFromID()
+	if !ok {
+		// Hidden source.
+		return
+	}
+	if _, err := storage.FindPeer(ctx, peerDB, from); err == nil {
+		// Already cached.
+		return
+	}
+
+	var p storage.Peer
+	switch from := from.(type) {
+	case *tg.PeerUser:
+		if u, ok := e.Users[from.UserID]; ok && !u.Min && p.FromUser(u) {
+			break
+		}
+		users, err := api.UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUserFromMessage{
+			Peer:   chat,
+			MsgID:  msg.ID,
+			UserID: from.UserID,
+		}})
+		if err != nil || len(users) == 0 || !p.FromUser(users[0]) {
+			lg.Debug("Forward source not cached", zap.Int64("user_id", from.UserID), zap.Error(err))
+			return
+		}
+	case *tg.PeerChannel:
+		if c, ok := e.Channels[from.ChannelID]; ok && p.FromChat(c) {
+			break
+		}
+		res, err := api.ChannelsGetChannels(ctx, []tg.InputChannelClass{&tg.InputChannelFromMessage{
+			Peer:      chat,
+			MsgID:     msg.ID,
+			ChannelID: from.ChannelID,
+		}})
+		if err != nil {
+			lg.Debug("Forward source not cached", zap.Int64("channel_id", from.ChannelID), zap.Error(err))
+			return
+		}
+		chats := res.GetChats()
+		if len(chats) == 0 || !p.FromChat(chats[0]) {
+			lg.Debug("Forward source not cached", zap.Int64("channel_id", from.ChannelID))
+			return
+		}
+	default:
+		return
+	}
+
+	if err := peerDB.Add(ctx, p); err != nil {
+		lg.Warn("Cache forward source", zap.Error(errors.Wrap(err, "add peer")))
+		return
+	}
+	lg.Debug("Forward source cached", zap.Stringer("peer", p))
+}
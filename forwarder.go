@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"sync/atomic"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/tg"
+)
+
+// forwarder forwards incoming private messages to target peer.
+type forwarder struct {
+	api *tg.Client
+	// Target is resolved after login, while updates can be handled
+	// before, so it is set atomically.
+	target atomic.Pointer[tg.InputPeerClass]
+}
+
+func newForwarder(api *tg.Client) *forwarder {
+	return &forwarder{api: api}
+}
+
+// SetTarget sets peer to forward to.
+func (f *forwarder) SetTarget(p tg.InputPeerClass) {
+	f.target.Store(&p)
+}
+
+// Forward forwards message of peer to target, if it is incoming private
+// message not from target itself.
+func (f *forwarder) Forward(ctx context.Context, p storage.Peer, msg *tg.Message) error {
+	target := f.target.Load()
+	if target == nil || msg.Out {
+		return nil
+	}
+	from := p.AsInputPeer()
+	fromKind, fromID, ok := inputPeerKey(from)
+	if !ok || fromKind != "user" {
+		return nil
+	}
+	if kind, id, ok := inputPeerKey(*target); ok && kind == fromKind && id == fromID {
+		// Avoiding loop.
+		return nil
+	}
+	var randomID int64
+	if err := binary.Read(rand.Reader, binary.LittleEndian, &randomID); err != nil {
+		return errors.Wrap(err, "random id")
+	}
+	if _, err := f.api.MessagesForwardMessages(ctx, &tg.MessagesForwardMessagesRequest{
+		FromPeer: from,
+		ID:       []int{msg.ID},
+		RandomID: []int64{randomID},
+		ToPeer:   *target,
+	}); err != nil {
+		return errors.Wrap(err, "forward")
+	}
+	return nil
+}
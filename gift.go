@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// printPremiumGift prints Telegram Premium gift received or sent in
+// private chat.
+func printPremiumGift(lg *zap.Logger, e tg.Entities, svc *tg.MessageService, action *tg.MessageActionGiftPremium) {
+	// Gift is sent in private chat, so peer is the other user.
+	from := "unknown"
+	if u, ok := svc.PeerID.(*tg.PeerUser); ok {
+		from = fmt.Sprintf("user %d", u.UserID)
+		if user, ok := e.Users[u.UserID]; ok {
+			from = user.FirstName
+			if user.Username != "" {
+				from += " (@" + user.Username + ")"
+			}
+		}
+	}
+	lg.Info("Premium gift",
+		zap.Int("msg_id", svc.ID),
+		zap.Bool("out", svc.Out),
+		zap.Int("months", action.Months),
+		zap.String("currency", action.Currency),
+		zap.Int64("amount", action.Amount),
+	)
+	if svc.Out {
+		fmt.Printf("Gifted %d months of Telegram Premium to %s\n", action.Months, from)
+		return
+	}
+	fmt.Printf("%s gifted you %d months of Telegram Premium\n", from, action.Months)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// handlerNames are names of update handlers that can be isolated.
+var handlerNames = []string{
+	"message",
+	"channel-message",
+	"join-requests",
+	"web-view",
+}
+
+// handlerQueue runs handler on its own goroutine, so slow handler does not
+// delay other handlers.
+//
+// Updates are handled in order they were queued.
+type handlerQueue struct {
+	name string
+	lg   *zap.Logger
+	jobs chan func(ctx context.Context) error
+}
+
+// Run handles queued updates until context is done, then handles all
+// updates left in queue, because updates state is already advanced for
+// them. Shutdown is blocked until queue is empty.
+func (q *handlerQueue) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			q.drain()
+			return ctx.Err()
+		case job := <-q.jobs:
+			q.handle(ctx, job)
+		}
+	}
+}
+
+func (q *handlerQueue) handle(ctx context.Context, job func(ctx context.Context) error) {
+	if err := job(ctx); err != nil {
+		q.lg.Error("Handler failed", zap.String("handler", q.name), zap.Error(err))
+	}
+}
+
+// drain handles updates left in queue with separate context, so they are
+// not canceled by shutdown.
+func (q *handlerQueue) drain() {
+	ctx := context.Background()
+	if n := len(q.jobs); n > 0 {
+		q.lg.Info("Draining handler queue", zap.String("handler", q.name), zap.Int("jobs", n))
+	}
+	for {
+		select {
+		case job := <-q.jobs:
+			q.handle(ctx, job)
+		default:
+			return
+		}
+	}
+}
+
+// handlerQueues are queues of isolated handlers by name.
+type handlerQueues map[string]*handlerQueue
+
+// parseHandlerQueues creates queues of given size for comma-separated
+// handler names.
+func parseHandlerQueues(lg *zap.Logger, names string, size int) (handlerQueues, error) {
+	queues := handlerQueues{}
+	if names == "" {
+		return queues, nil
+	}
+	if size <= 0 {
+		return nil, errors.New("handler queue size should be positive")
+	}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		known := false
+		for _, n := range handlerNames {
+			known = known || n == name
+		}
+		if !known {
+			return nil, errors.Errorf("unknown handler %q, expected one of %s", name, strings.Join(handlerNames, ", "))
+		}
+		queues[name] = &handlerQueue{
+			name: name,
+			lg:   lg,
+			jobs: make(chan func(ctx context.Context) error, size),
+		}
+	}
+	return queues, nil
+}
+
+// Run runs all queues until context is done.
+func (qs handlerQueues) Run(ctx context.Context, run func(func() error)) {
+	for _, q := range qs {
+		q := q
+		run(func() error {
+			return q.Run(ctx)
+		})
+	}
+}
+
+// isolate returns handler that queues updates to queue instead of handling
+// them in place, or h itself if queue is nil.
+//
+// Dispatcher is blocked only if queue is full. Error of isolated handler is
+// logged instead of being returned to updates engine.
+//
+// NB: isolation gives up at-least-once delivery. Updates state is advanced
+// as soon as update is queued, so update whose handler fails is not
+// redelivered, even with -dedupe or bookmark.
+func isolate[U tg.UpdateClass](q *handlerQueue, h func(context.Context, tg.Entities, U) error) func(context.Context, tg.Entities, U) error {
+	if q == nil {
+		return h
+	}
+	return func(ctx context.Context, e tg.Entities, u U) error {
+		select {
+		case q.jobs <- func(ctx context.Context) error { return h(ctx, e, u) }:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+// serveHTTP starts HTTP server of handler on addr, returned function
+// shuts it down.
+func serveHTTP(addr string, h http.Handler) (func() error, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "listen")
+	}
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			return errors.Wrap(err, "shutdown")
+		}
+		if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+			return errors.Wrap(err, "serve")
+		}
+		return nil
+	}, nil
+}
+
+// pprofHandler returns handler of profiles on /debug/pprof/.
+//
+// Dedicated mux is used instead of http.DefaultServeMux, so nothing else
+// is exposed.
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// health is health state of process for orchestration probes.
+type health struct {
+	accounts int32
+	ready    atomic.Int32
+}
+
+func newHealth(accounts int) *health {
+	return &health{accounts: int32(accounts)}
+}
+
+// Ready marks updates handler of account as started.
+func (h *health) Ready() {
+	h.ready.Add(1)
+}
+
+// Handler returns handler of /healthz, which is always OK, and /readyz,
+// which is OK once updates handlers of all accounts are started.
+func (h *health) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if h.ready.Load() < h.accounts {
+			http.Error(w, "updates handler is not started", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	return mux
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// inviteOptions are options of created invite link.
+type inviteOptions struct {
+	Expire        time.Duration
+	UsageLimit    int
+	RequestNeeded bool
+	Title         string
+}
+
+func (o inviteOptions) validate() error {
+	if o.Expire < 0 {
+		return errors.New("negative expiration")
+	}
+	if o.UsageLimit < 0 || o.UsageLimit > 99999 {
+		return errors.Errorf("usage limit %d is out of range [0, 99999]", o.UsageLimit)
+	}
+	if o.UsageLimit > 0 && o.RequestNeeded {
+		return errors.New("usage limit can't be used with join requests")
+	}
+	return nil
+}
+
+// printInvite prints invite link with its properties.
+func printInvite(invite tg.ExportedChatInviteClass) {
+	i, ok := invite.(*tg.ChatInviteExported)
+	if !ok {
+		fmt.Printf("%T\n", invite)
+		return
+	}
+	fmt.Print(i.Link)
+	if i.Title != "" {
+		fmt.Printf(" %q", i.Title)
+	}
+	if i.UsageLimit > 0 {
+		fmt.Printf(" used %d/%d", i.Usage, i.UsageLimit)
+	} else {
+		fmt.Printf(" used %d", i.Usage)
+	}
+	if i.ExpireDate > 0 {
+		fmt.Printf(" expires %s", time.Unix(int64(i.ExpireDate), 0).Format(time.DateTime))
+	}
+	if i.RequestNeeded {
+		fmt.Printf(" (approval required, %d pending)", i.Requested)
+	}
+	if i.Permanent {
+		fmt.Print(" (permanent)")
+	}
+	if i.Revoked {
+		fmt.Print(" (revoked)")
+	}
+	fmt.Println()
+}
+
+// createInvite creates new invite link to given chat.
+func createInvite(ctx context.Context, api *tg.Client, p tg.InputPeerClass, opt inviteOptions) error {
+	if err := opt.validate(); err != nil {
+		return err
+	}
+	req := &tg.MessagesExportChatInviteRequest{
+		Peer:          p,
+		UsageLimit:    opt.UsageLimit,
+		RequestNeeded: opt.RequestNeeded,
+		Title:         opt.Title,
+	}
+	if opt.Expire > 0 {
+		req.ExpireDate = int(time.Now().Add(opt.Expire).Unix())
+	}
+	invite, err := api.MessagesExportChatInvite(ctx, req)
+	if err != nil {
+		return errors.Wrap(err, "export invite")
+	}
+	printInvite(invite)
+	return nil
+}
+
+// invitesPageSize is number of invite links fetched per call.
+const invitesPageSize = 100
+
+// listInvites prints invite links to given chat created by current user.
+func listInvites(ctx context.Context, api *tg.Client, p tg.InputPeerClass) error {
+	for _, revoked := range []bool{false, true} {
+		req := &tg.MessagesGetExportedChatInvitesRequest{
+			Revoked: revoked,
+			Peer:    p,
+			AdminID: &tg.InputUserSelf{},
+			Limit:   invitesPageSize,
+		}
+		for {
+			res, err := api.MessagesGetExportedChatInvites(ctx, req)
+			if err != nil {
+				return errors.Wrap(err, "get invites")
+			}
+			for _, invite := range res.Invites {
+				printInvite(invite)
+			}
+			if len(res.Invites) < invitesPageSize {
+				break
+			}
+			// Next page starts after last link of current one.
+			last, ok := res.Invites[len(res.Invites)-1].(*tg.ChatInviteExported)
+			if !ok {
+				break
+			}
+			req.SetOffsetDate(last.Date)
+			req.SetOffsetLink(last.Link)
+		}
+	}
+	return nil
+}
+
+// revokeInvite revokes invite link to given chat.
+func revokeInvite(ctx context.Context, api *tg.Client, p tg.InputPeerClass, link string) error {
+	if _, err := api.MessagesEditExportedChatInvite(ctx, &tg.MessagesEditExportedChatInviteRequest{
+		Revoked: true,
+		Peer:    p,
+		Link:    link,
+	}); err != nil {
+		return errors.Wrap(err, "revoke invite")
+	}
+	fmt.Println("Revoked", link)
+	return nil
+}
+
+// inviteHash extracts hash from invite link like t.me/+hash,
+// t.me/joinchat/hash or tg://join?invite=hash. Bare hash is returned as-is.
+func inviteHash(link string) (string, error) {
+	link = strings.TrimSpace(link)
+	if strings.HasPrefix(link, "tg:") {
+		u, err := url.Parse(link)
+		if err != nil {
+			return "", errors.Wrap(err, "parse link")
+		}
+		if h := u.Query().Get("invite"); h != "" {
+			return h, nil
+		}
+		return "", errors.Errorf("invalid invite link %q", link)
+	}
+	for _, prefix := range []string{"https://", "http://"} {
+		link = strings.TrimPrefix(link, prefix)
+	}
+	for _, host := range []string{"t.me/", "telegram.me/", "telegram.dog/"} {
+		if !strings.HasPrefix(link, host) {
+			continue
+		}
+		path := strings.TrimPrefix(link, host)
+		switch {
+		case strings.HasPrefix(path, "+"):
+			return strings.TrimPrefix(path, "+"), nil
+		case strings.HasPrefix(path, "joinchat/"):
+			return strings.TrimPrefix(path, "joinchat/"), nil
+		default:
+			return "", errors.Errorf("not an invite link %q", link)
+		}
+	}
+	if strings.ContainsAny(link, "/?") {
+		return "", errors.Errorf("invalid invite link %q", link)
+	}
+	return link, nil
+}
+
+// printInviteInfo prints info about chat of invite link without joining.
+func printInviteInfo(ctx context.Context, api *tg.Client, link string) error {
+	hash, err := inviteHash(link)
+	if err != nil {
+		return err
+	}
+	res, err := api.MessagesCheckChatInvite(ctx, hash)
+	switch {
+	case tgerr.Is(err, "INVITE_HASH_EXPIRED"):
+		return errors.New("invite link is expired")
+	case tgerr.Is(err, "INVITE_HASH_INVALID", "INVITE_HASH_EMPTY"):
+		return errors.New("invite link is invalid")
+	case err != nil:
+		return errors.Wrap(err, "check invite")
+	}
+
+	switch v := res.(type) {
+	case *tg.ChatInviteAlready:
+		fmt.Println("Already a member of", chatTitle(v.Chat))
+	case *tg.ChatInvitePeek:
+		fmt.Println("Preview of", chatTitle(v.Chat))
+		fmt.Println("Preview expires:", time.Unix(int64(v.Expires), 0).Format(time.DateTime))
+	case *tg.ChatInvite:
+		kind := "group"
+		switch {
+		case v.Broadcast:
+			kind = "channel"
+		case v.Megagroup:
+			kind = "supergroup"
+		}
+		fmt.Printf("Title: %s (%s)\n", v.Title, kind)
+		if v.About != "" {
+			fmt.Println("About:", v.About)
+		}
+		fmt.Println("Members:", v.ParticipantsCount)
+		fmt.Println("Public:", v.Public)
+		fmt.Println("Approval required:", v.RequestNeeded)
+	default:
+		return errors.Errorf("unexpected invite %T", res)
+	}
+	return nil
+}
+
+func chatTitle(c tg.ChatClass) string {
+	switch c := c.(type) {
+	case *tg.Chat:
+		return c.Title
+	case *tg.Channel:
+		return c.Title
+	default:
+		return fmt.Sprintf("chat %d", c.GetID())
+	}
+}
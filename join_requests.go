@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// Join request handling modes.
+const (
+	joinLog      = ""         // only log requests
+	joinApprove  = "approve"  // approve matching requests, leave others pending
+	joinModerate = "moderate" // approve matching requests, decline others
+)
+
+// joinRequestsPageSize is number of join requests fetched per call.
+const joinRequestsPageSize = 100
+
+// joinRequestKey identifies join request. Request made again after decline
+// has new date, so it is reported again.
+type joinRequestKey struct {
+	chatID int64
+	userID int64
+	date   int
+}
+
+// joinRequests handles pending join requests to admined chats.
+type joinRequests struct {
+	api     *tg.Client
+	lg      *zap.Logger
+	mode    string
+	limiter *rate.Limiter
+
+	// reported are pending requests that were already handled, so they are
+	// not reported again on every update of pending requests.
+	mux      sync.Mutex
+	reported map[joinRequestKey]struct{}
+
+	// RequireUsername requires user to have username to be approved.
+	RequireUsername bool
+}
+
+func newJoinRequests(api *tg.Client, lg *zap.Logger, mode string, limit rate.Limit) (*joinRequests, error) {
+	switch mode {
+	case joinLog, joinApprove, joinModerate:
+	default:
+		return nil, errors.Errorf("unknown join requests mode %q", mode)
+	}
+	return &joinRequests{
+		api:      api,
+		lg:       lg,
+		mode:     mode,
+		limiter:  rate.NewLimiter(limit, 1),
+		reported: map[joinRequestKey]struct{}{},
+	}, nil
+}
+
+// match reports whether join request of given user should be approved.
+func (j *joinRequests) match(user *tg.User) bool {
+	if j.RequireUsername && user.Username == "" {
+		return false
+	}
+	return true
+}
+
+// Handle logs join request of user to chat and applies mode.
+func (j *joinRequests) Handle(ctx context.Context, p tg.InputPeerClass, user *tg.User, about string) error {
+	name := user.FirstName
+	if user.Username != "" {
+		name += " (@" + user.Username + ")"
+	}
+	j.lg.Info("Join request",
+		zap.Int64("user_id", user.ID),
+		zap.String("username", user.Username),
+		zap.String("about", about),
+	)
+	fmt.Printf("Join request from %s: %s\n", name, about)
+
+	approve := j.match(user)
+	switch {
+	case j.mode == joinLog:
+		return nil
+	case !approve && j.mode != joinModerate:
+		// Leaving request pending for manual review.
+		return nil
+	}
+
+	// Approving or declining slowly to avoid flood waits on join bursts.
+	if err := j.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	if _, err := j.api.MessagesHideChatJoinRequest(ctx, &tg.MessagesHideChatJoinRequestRequest{
+		Approved: approve,
+		Peer:     p,
+		UserID:   user.AsInput(),
+	}); err != nil {
+		if tgerr.Is(err, "HIDE_REQUESTER_MISSING") {
+			// Already handled by other admin.
+			return nil
+		}
+		return errors.Wrap(err, "hide join request")
+	}
+	if approve {
+		fmt.Printf("Approved %s\n", name)
+	} else {
+		fmt.Printf("Declined %s\n", name)
+	}
+	return nil
+}
+
+func (j *joinRequests) isReported(k joinRequestKey) bool {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	_, ok := j.reported[k]
+	return ok
+}
+
+func (j *joinRequests) setReported(k joinRequestKey) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	j.reported[k] = struct{}{}
+}
+
+// HandlePending handles pending join requests of given chat that were not
+// handled yet.
+//
+// Used by user accounts, which receive only count and recent requesters.
+func (j *joinRequests) HandlePending(ctx context.Context, p tg.InputPeerClass) error {
+	_, chatID, _ := inputPeerKey(p)
+	req := &tg.MessagesGetChatInviteImportersRequest{
+		Requested:  true,
+		Peer:       p,
+		OffsetUser: &tg.InputUserEmpty{},
+		Limit:      joinRequestsPageSize,
+	}
+	for {
+		res, err := j.api.MessagesGetChatInviteImporters(ctx, req)
+		if err != nil {
+			return errors.Wrap(err, "get join requests")
+		}
+		users := tg.UserClassArray(res.Users).UserToMap()
+		for _, importer := range res.Importers {
+			user, ok := users[importer.UserID]
+			if !ok {
+				continue
+			}
+			k := joinRequestKey{chatID: chatID, userID: user.ID, date: importer.Date}
+			if j.isReported(k) {
+				continue
+			}
+			if err := j.Handle(ctx, p, user, importer.About); err != nil {
+				return err
+			}
+			j.setReported(k)
+		}
+		if len(res.Importers) < joinRequestsPageSize {
+			return nil
+		}
+		// Next page starts after last request of current one.
+		last := res.Importers[len(res.Importers)-1]
+		user, ok := users[last.UserID]
+		if !ok {
+			return errors.Errorf("user %d of join request not found", last.UserID)
+		}
+		req.OffsetDate = last.Date
+		req.OffsetUser = user.AsInput()
+	}
+}
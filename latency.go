@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// latencyStats accumulates end-to-end latency of incoming messages, i.e.
+// difference between server-side message date and local receipt time.
+//
+// Message date has second precision, so latency is precise up to a second
+// too. Local clock can also be skewed from server time, so minimal
+// observed latency is reported as skew estimate: in normal conditions
+// it is close to zero.
+type latencyStats struct {
+	mux     sync.Mutex
+	samples []time.Duration
+}
+
+// Observe records latency of message with given date.
+func (s *latencyStats) Observe(date int, now time.Time) {
+	d := now.Sub(time.Unix(int64(date), 0))
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.samples = append(s.samples, d)
+}
+
+// flush returns collected samples sorted ascending and resets stats.
+func (s *latencyStats) flush() []time.Duration {
+	s.mux.Lock()
+	samples := s.samples
+	s.samples = nil
+	s.mux.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples
+}
+
+// Run periodically reports latency distribution until context is done.
+func (s *latencyStats) Run(ctx context.Context, lg *zap.Logger, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		samples := s.flush()
+		if len(samples) == 0 {
+			continue
+		}
+		percentile := func(p float64) time.Duration {
+			return samples[int(float64(len(samples)-1)*p)]
+		}
+		var (
+			p50  = percentile(0.5)
+			p95  = percentile(0.95)
+			max  = samples[len(samples)-1]
+			skew = samples[0]
+		)
+		lg.Info("Update latency",
+			zap.Int("count", len(samples)),
+			zap.Duration("p50", p50),
+			zap.Duration("p95", p95),
+			zap.Duration("max", max),
+			zap.Duration("skew", skew),
+		)
+		fmt.Printf("Latency of %d messages: p50=%s p95=%s max=%s (clock skew ~%s)\n",
+			len(samples), p50, p95, max, skew,
+		)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/go-faster/errors"
+	lj "gopkg.in/natefinch/lumberjack.v2"
+)
+
+// logRotation are options of log file rotation.
+type logRotation struct {
+	MaxSize    int // megabytes
+	MaxBackups int
+	MaxAge     int // days
+	Compress   bool
+}
+
+// logRotationEnv maps flags of log rotation to environment variables.
+var logRotationEnv = map[string]string{
+	"log-max-size":    "LOG_MAX_SIZE",
+	"log-max-backups": "LOG_MAX_BACKUPS",
+	"log-max-age":     "LOG_MAX_AGE",
+	"log-compress":    "LOG_COMPRESS",
+}
+
+// applyEnv sets flags of log rotation that are not set explicitly from
+// environment variables.
+func (r *logRotation) applyEnv() error {
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for name, env := range logRotationEnv {
+		v, ok := os.LookupEnv(env)
+		if !ok || explicit[name] {
+			continue
+		}
+		if err := flag.Set(name, v); err != nil {
+			return errors.Wrapf(err, "%s", env)
+		}
+	}
+	return nil
+}
+
+func (r logRotation) validate() error {
+	for name, v := range map[string]int{
+		"-log-max-size":    r.MaxSize,
+		"-log-max-backups": r.MaxBackups,
+		"-log-max-age":     r.MaxAge,
+	} {
+		if v < 0 {
+			return errors.Errorf("%s should be non-negative, got %d", name, v)
+		}
+	}
+	return nil
+}
+
+// Logger returns rotating logger of file.
+func (r logRotation) Logger(path string) *lj.Logger {
+	return &lj.Logger{
+		Filename:   path,
+		MaxSize:    r.MaxSize,
+		MaxBackups: r.MaxBackups,
+		MaxAge:     r.MaxAge,
+		Compress:   r.Compress,
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-faster/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelFilterCore passes only entries with levels enabled by filter.
+type levelFilterCore struct {
+	zapcore.Core
+	filter zapcore.LevelEnabler
+}
+
+func (c levelFilterCore) Enabled(l zapcore.Level) bool {
+	return c.filter.Enabled(l) && c.Core.Enabled(l)
+}
+
+func (c levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelFilterCore{Core: c.Core.With(fields), filter: c.filter}
+}
+
+func (c levelFilterCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.filter.Enabled(e.Level) {
+		return ce
+	}
+	return c.Core.Check(e, ce)
+}
+
+// sampleCore wraps core with sampler that logs first initial entries with
+// the same level and message every second and then every thereafter-th.
+//
+// Errors are never sampled.
+func sampleCore(core zapcore.Core, initial, thereafter string) (zapcore.Core, error) {
+	first, err := strconv.Atoi(initial)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse initial")
+	}
+	next := 0
+	if thereafter != "" {
+		if next, err = strconv.Atoi(thereafter); err != nil {
+			return nil, errors.Wrap(err, "parse thereafter")
+		}
+	}
+	sampled := zapcore.NewSamplerWithOptions(levelFilterCore{
+		Core: core,
+		filter: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l < zapcore.ErrorLevel
+		}),
+	}, time.Second, first, next)
+	errs := levelFilterCore{
+		Core: core,
+		filter: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= zapcore.ErrorLevel
+		}),
+	}
+	return zapcore.NewTee(sampled, errs), nil
+}
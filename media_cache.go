@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/downloader"
+	"github.com/gotd/td/tg"
+	"go.uber.org/multierr"
+	"golang.org/x/sync/singleflight"
+)
+
+// mediaIndexPrefix is key prefix of media cache index in pebble database.
+var mediaIndexPrefix = []byte("media/")
+
+// mediaCache is content-addressed cache of downloaded files.
+//
+// Files are stored under SHA256 of their content, so the same media
+// downloaded via different messages is stored once. Index in pebble
+// database maps media (e.g. document or photo ID) to content hash.
+type mediaCache struct {
+	dir   string
+	db    *pebbledb.DB
+	group singleflight.Group
+}
+
+func newMediaCache(dir string, db *pebbledb.DB) *mediaCache {
+	return &mediaCache{dir: dir, db: db}
+}
+
+func (c *mediaCache) path(hash string) string {
+	return filepath.Join(c.dir, hash[:2], hash)
+}
+
+func (c *mediaCache) lookup(key []byte) (_ string, _ bool, rerr error) {
+	data, closer, err := c.db.Get(key)
+	if errors.Is(err, pebbledb.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errors.Wrap(err, "get")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, closer.Close())
+	}()
+	p := c.path(string(data))
+	if _, err := os.Stat(p); err != nil {
+		// File was removed, downloading again.
+		return "", false, nil
+	}
+	return p, true, nil
+}
+
+// Get returns path to cached file of media with given key, downloading it
+// via fetch if not cached.
+//
+// Concurrent calls with the same key share single download.
+func (c *mediaCache) Get(key string, fetch func(w io.Writer) error) (string, error) {
+	indexKey := append(append([]byte(nil), mediaIndexPrefix...), key...)
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		if p, ok, err := c.lookup(indexKey); err != nil || ok {
+			return p, err
+		}
+		hash, err := c.store(fetch)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.db.Set(indexKey, []byte(hash), nil); err != nil {
+			return nil, errors.Wrap(err, "set index")
+		}
+		return c.path(hash), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// store downloads file to temporary file and moves it to content-addressed
+// path, returning content hash.
+func (c *mediaCache) store(fetch func(w io.Writer) error) (_ string, rerr error) {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return "", errors.Wrap(err, "create dir")
+	}
+	// Temporary file is in the same directory, so rename is atomic and
+	// partially downloaded file is never visible under content path.
+	tmp, err := os.CreateTemp(c.dir, "download-*")
+	if err != nil {
+		return "", errors.Wrap(err, "create temp")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, tmp.Close())
+		// No-op if renamed.
+		_ = os.Remove(tmp.Name())
+	}()
+
+	h := sha256.New()
+	if err := fetch(io.MultiWriter(tmp, h)); err != nil {
+		return "", errors.Wrap(err, "download")
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+	p := c.path(hash)
+	if _, err := os.Stat(p); err == nil {
+		// Same content is already stored.
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return "", errors.Wrap(err, "create dir")
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return "", errors.Wrap(err, "rename")
+	}
+	return hash, nil
+}
+
+// downloadProfilePhoto downloads current profile photo of peer via cache
+// and returns path to it.
+func downloadProfilePhoto(ctx context.Context, api *tg.Client, cache *mediaCache, p tg.InputPeerClass) (string, error) {
+	photoID, err := peerPhotoID(ctx, api, p)
+	if err != nil {
+		return "", err
+	}
+	return cache.Get("photo/"+strconv.FormatInt(photoID, 10), func(w io.Writer) error {
+		_, err := downloader.NewDownloader().Download(api, &tg.InputPeerPhotoFileLocation{
+			Big:     true,
+			Peer:    p,
+			PhotoID: photoID,
+		}).Stream(ctx, w)
+		return err
+	})
+}
+
+// peerPhotoID returns ID of current profile photo of peer.
+func peerPhotoID(ctx context.Context, api *tg.Client, p tg.InputPeerClass) (int64, error) {
+	switch p := p.(type) {
+	case *tg.InputPeerUser:
+		users, err := api.UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUser{
+			UserID:     p.UserID,
+			AccessHash: p.AccessHash,
+		}})
+		if err != nil {
+			return 0, errors.Wrap(err, "get user")
+		}
+		if len(users) == 0 {
+			return 0, errors.New("user not found")
+		}
+		if u, ok := users[0].(*tg.User); ok {
+			if photo, ok := u.Photo.(*tg.UserProfilePhoto); ok {
+				return photo.PhotoID, nil
+			}
+		}
+	case *tg.InputPeerChannel:
+		res, err := api.ChannelsGetChannels(ctx, []tg.InputChannelClass{&tg.InputChannel{
+			ChannelID:  p.ChannelID,
+			AccessHash: p.AccessHash,
+		}})
+		if err != nil {
+			return 0, errors.Wrap(err, "get channel")
+		}
+		for _, c := range res.GetChats() {
+			if c, ok := c.(*tg.Channel); ok {
+				if photo, ok := c.Photo.(*tg.ChatPhoto); ok {
+					return photo.PhotoID, nil
+				}
+			}
+		}
+	case *tg.InputPeerChat:
+		res, err := api.MessagesGetChats(ctx, []int64{p.ChatID})
+		if err != nil {
+			return 0, errors.Wrap(err, "get chat")
+		}
+		for _, c := range res.GetChats() {
+			if c, ok := c.(*tg.Chat); ok {
+				if photo, ok := c.Photo.(*tg.ChatPhoto); ok {
+					return photo.PhotoID, nil
+				}
+			}
+		}
+	default:
+		return 0, errors.Errorf("unsupported peer %T", p)
+	}
+	return 0, errors.New("no profile photo")
+}
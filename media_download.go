@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/downloader"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// mediaDownloader saves photos and documents of incoming messages to
+// per-peer directories.
+//
+// Files are downloaded to media cache and linked to per-peer directories,
+// so the same media received in many messages is downloaded and stored
+// once.
+//
+// Downloads go through the same API client, so rate limit and flood wait
+// middlewares apply.
+type mediaDownloader struct {
+	api   *tg.Client
+	lg    *zap.Logger
+	dir   string
+	cache *mediaCache
+	d     *downloader.Downloader
+}
+
+func newMediaDownloader(api *tg.Client, lg *zap.Logger, dir string, cache *mediaCache) *mediaDownloader {
+	return &mediaDownloader{
+		api:   api,
+		lg:    lg,
+		dir:   dir,
+		cache: cache,
+		d:     downloader.NewDownloader(),
+	}
+}
+
+// mediaFile returns file location, name and cache key of message media,
+// if any.
+func mediaFile(msg *tg.Message) (tg.InputFileLocationClass, string, string, bool) {
+	switch m := msg.Media.(type) {
+	case *tg.MessageMediaPhoto:
+		photo, ok := m.Photo.(*tg.Photo)
+		if !ok {
+			return nil, "", "", false
+		}
+		// Largest size is the last one.
+		var size string
+		for _, s := range photo.Sizes {
+			switch s := s.(type) {
+			case *tg.PhotoSize:
+				size = s.Type
+			case *tg.PhotoSizeProgressive:
+				size = s.Type
+			}
+		}
+		if size == "" {
+			return nil, "", "", false
+		}
+		return &tg.InputPhotoFileLocation{
+			ID:            photo.ID,
+			AccessHash:    photo.AccessHash,
+			FileReference: photo.FileReference,
+			ThumbSize:     size,
+		}, fmt.Sprintf("photo_%d.jpg", photo.ID), fmt.Sprintf("photo/%d", photo.ID), true
+	case *tg.MessageMediaDocument:
+		doc, ok := m.Document.(*tg.Document)
+		if !ok {
+			return nil, "", "", false
+		}
+		name := fmt.Sprintf("document_%d", doc.ID)
+		if exts, _ := mime.ExtensionsByType(doc.MimeType); len(exts) > 0 {
+			name += exts[0]
+		}
+		for _, attr := range doc.Attributes {
+			if attr, ok := attr.(*tg.DocumentAttributeFilename); ok && attr.FileName != "" {
+				// File name is controlled by sender.
+				name = filepath.Base(filepath.Clean("/" + attr.FileName))
+			}
+		}
+		return &tg.InputDocumentFileLocation{
+			ID:            doc.ID,
+			AccessHash:    doc.AccessHash,
+			FileReference: doc.FileReference,
+		}, name, fmt.Sprintf("doc/%d", doc.ID), true
+	default:
+		return nil, "", "", false
+	}
+}
+
+// Handle downloads media of message, if any.
+func (m *mediaDownloader) Handle(ctx context.Context, p storage.Peer, msg *tg.Message) error {
+	loc, name, key, ok := mediaFile(msg)
+	if !ok {
+		return nil
+	}
+	kind, id, ok := inputPeerKey(p.AsInputPeer())
+	if !ok {
+		return nil
+	}
+	dir := filepath.Join(m.dir, fmt.Sprintf("%s_%d", kind, id))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "create dir")
+	}
+	// Prefixing by message ID, so files with the same name do not clash.
+	path := filepath.Join(dir, fmt.Sprintf("%d_%s", msg.ID, name))
+	if _, err := os.Lstat(path); err == nil {
+		return nil
+	}
+	if err := m.save(ctx, loc, key, path); err != nil {
+		return errors.Wrapf(err, "download %s", name)
+	}
+	m.lg.Info("Media downloaded", zap.Int("msg_id", msg.ID), zap.String("path", path))
+	return nil
+}
+
+// save downloads file to cache with given key, if not cached, and links
+// path to cached file.
+func (m *mediaDownloader) save(ctx context.Context, loc tg.InputFileLocationClass, key, path string) error {
+	cached, err := m.cache.Get(key, func(w io.Writer) error {
+		_, err := m.d.Download(m.api, loc).Stream(ctx, w)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// Hard link shares storage with cache and stays valid if cache is
+	// cleaned up. Symbolic link is fallback for other file system.
+	if err := os.Link(cached, path); err != nil {
+		target, err := filepath.Abs(cached)
+		if err != nil {
+			return errors.Wrap(err, "abs")
+		}
+		if err := os.Symlink(target, path); err != nil {
+			return errors.Wrap(err, "link")
+		}
+	}
+	return nil
+}
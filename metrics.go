@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics are Prometheus metrics of client.
+//
+// Metrics are shared by all accounts of process, so collectors are
+// registered once, see getMetrics.
+type metrics struct {
+	registry     *prometheus.Registry
+	floodWaits   prometheus.Counter
+	floodWait    prometheus.Histogram
+	messages     *prometheus.CounterVec
+	calls        *prometheus.CounterVec
+	callDuration *prometheus.HistogramVec
+	connections  *prometheus.CounterVec
+	traffic      *prometheus.CounterVec
+	sendQueue    *prometheus.GaugeVec
+}
+
+var (
+	metricsOnce sync.Once
+	metricsInst *metrics
+)
+
+// getMetrics returns metrics of process, registering them on first call.
+func getMetrics() *metrics {
+	metricsOnce.Do(func() {
+		m := &metrics{
+			registry: prometheus.NewRegistry(),
+			floodWaits: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "telegram_flood_waits_total",
+				Help: "Number of FLOOD_WAIT errors.",
+			}),
+			floodWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+				Name:    "telegram_flood_wait_seconds",
+				Help:    "Duration of FLOOD_WAIT.",
+				Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+			}),
+			messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "telegram_messages_total",
+				Help: "Number of new messages by peer type.",
+			}, []string{"peer_type"}),
+			calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "telegram_rpc_calls_total",
+				Help: "Number of RPC calls by method and result.",
+			}, []string{"method", "result"}),
+			callDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Name:    "telegram_rpc_duration_seconds",
+				Help:    "Duration of RPC calls by method, including flood waits and retries.",
+				Buckets: prometheus.DefBuckets,
+			}, []string{"method"}),
+			connections: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "telegram_connection_events_total",
+				Help: "Number of connection state transitions by DC and event.",
+			}, []string{"dc", "event"}),
+			traffic: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "telegram_rpc_bytes_total",
+				Help: "Size of TL-encoded RPC payloads by method and direction (sent, received), counted with -netstats.",
+			}, []string{"method", "direction"}),
+			sendQueue: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "telegram_send_queue_depth",
+				Help: "Number of messages in send queue by session.",
+			}, []string{"session"}),
+		}
+		m.registry.MustRegister(
+			m.floodWaits,
+			m.floodWait,
+			m.messages,
+			m.calls,
+			m.callDuration,
+			m.connections,
+			m.traffic,
+			m.sendQueue,
+			prometheus.NewGoCollector(),
+			prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		)
+		metricsInst = m
+	})
+	return metricsInst
+}
+
+// FloodWait records flood wait of given duration.
+func (m *metrics) FloodWait(d time.Duration) {
+	m.floodWaits.Inc()
+	m.floodWait.Observe(d.Seconds())
+}
+
+// Connection records connection state transition to DC.
+func (m *metrics) Connection(dc int, event string) {
+	m.connections.WithLabelValues(strconv.Itoa(dc), event).Inc()
+}
+
+// Traffic records sizes of RPC call payloads.
+func (m *metrics) Traffic(method string, sent, received int64) {
+	m.traffic.WithLabelValues(method, "sent").Add(float64(sent))
+	m.traffic.WithLabelValues(method, "received").Add(float64(received))
+}
+
+// SendQueueDepth records number of messages in send queue of session.
+func (m *metrics) SendQueueDepth(session string, n int) {
+	m.sendQueue.WithLabelValues(session).Set(float64(n))
+}
+
+// Message records new message from peer.
+func (m *metrics) Message(p tg.InputPeerClass) {
+	kind, _, ok := inputPeerKey(p)
+	if !ok {
+		kind = "unknown"
+	}
+	m.messages.WithLabelValues(kind).Inc()
+}
+
+// methodName returns TL type name of RPC request.
+func methodName(input bin.Encoder) string {
+	if v, ok := input.(interface{ TypeName() string }); ok {
+		return v.TypeName()
+	}
+	return fmt.Sprintf("%T", input)
+}
+
+// Middleware returns middleware that records RPC calls.
+func (m *metrics) Middleware() telegram.Middleware {
+	return telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+		return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+			method := methodName(input)
+			start := time.Now()
+			err := next.Invoke(ctx, input, output)
+			m.callDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+			result := "ok"
+			if err != nil {
+				result = "error"
+			}
+			m.calls.WithLabelValues(method, result).Inc()
+			return err
+		}
+	})
+}
+
+// metricsHandler returns handler of metrics on /metrics.
+func metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(getMetrics().registry, promhttp.HandlerOpts{}))
+	return mux
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/session"
+	"github.com/gotd/td/telegram"
+	"go.uber.org/zap"
+)
+
+// checkSessionDC verifies that session storage was updated with DC client
+// is connected to after possible migration, so next run connects to it
+// directly instead of migrating again.
+func checkSessionDC(ctx context.Context, lg *zap.Logger, client *telegram.Client, storage session.Storage) error {
+	dc := client.Config().ThisDC
+	if dc == 0 {
+		// Config is not fetched yet.
+		return nil
+	}
+	data, err := (&session.Loader{Storage: storage}).Load(ctx)
+	if errors.Is(err, session.ErrNotFound) {
+		lg.Warn("Session is not persisted", zap.Int("dc", dc))
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "load session")
+	}
+	if data.DC != dc {
+		lg.Warn("Session DC is outdated, migration was not persisted",
+			zap.Int("session_dc", data.DC),
+			zap.Int("dc", dc),
+		)
+		fmt.Printf("Warning: session has DC %d, but connected to DC %d\n", data.DC, dc)
+		return nil
+	}
+	lg.Debug("Session DC is up to date", zap.Int("dc", dc))
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// parseMTProxy parses MTProto proxy address of form host:port:secret.
+//
+// Secret is hex or base64 encoded, either simple (16 bytes), padded
+// ("dd" prefix) or fake-TLS ("ee" prefix, followed by domain).
+func parseMTProxy(s string) (string, []byte, error) {
+	idx := strings.LastIndexByte(s, ':')
+	if idx < 0 {
+		return "", nil, errors.New("expected host:port:secret")
+	}
+	addr, rawSecret := s[:idx], s[idx+1:]
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return "", nil, errors.Wrapf(err, "invalid address %q", addr)
+	}
+	secret, err := decodeMTProxySecret(rawSecret)
+	if err != nil {
+		return "", nil, err
+	}
+	return addr, secret, nil
+}
+
+// decodeMTProxySecret decodes and validates length of proxy secret.
+func decodeMTProxySecret(s string) ([]byte, error) {
+	const keyLength = 16
+	secret, err := hex.DecodeString(s)
+	if err != nil {
+		// Secrets of fake-TLS proxies are usually shared in base64.
+		for _, enc := range []*base64.Encoding{
+			base64.RawURLEncoding,
+			base64.URLEncoding,
+			base64.RawStdEncoding,
+			base64.StdEncoding,
+		} {
+			if secret, err = enc.DecodeString(s); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return nil, errors.New("secret is neither hex nor base64")
+		}
+	}
+	switch {
+	case len(secret) == keyLength:
+		return secret, nil
+	case len(secret) > 0 && secret[0] == 0xdd:
+		if len(secret) != keyLength+1 {
+			return nil, errors.Errorf("padded secret should be %d bytes, got %d", keyLength+1, len(secret))
+		}
+		return secret, nil
+	case len(secret) > 0 && secret[0] == 0xee:
+		if len(secret) <= keyLength+1 {
+			return nil, errors.Errorf("fake-TLS secret should contain domain after %d bytes, got %d", keyLength+1, len(secret))
+		}
+		return secret, nil
+	default:
+		return nil, errors.Errorf("secret should be %d bytes, or prefixed by dd or ee, got %d bytes", keyLength, len(secret))
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// methodStats is data usage of single RPC method.
+type methodStats struct {
+	Calls    int
+	Errors   int
+	Sent     int64
+	Received int64
+}
+
+// netStats counts RPC calls and payload sizes by method.
+//
+// Sizes are sizes of TL-encoded payloads, without MTProto overhead
+// like encryption, padding and acknowledgements, so actual traffic is
+// slightly bigger.
+type netStats struct {
+	metrics *metrics
+	mux     sync.Mutex
+	methods map[string]*methodStats
+}
+
+func newNetStats(m *metrics) *netStats {
+	return &netStats{metrics: m, methods: map[string]*methodStats{}}
+}
+
+// encodedSize returns size of TL-encoded value, if it can be encoded.
+func encodedSize(v any) int64 {
+	e, ok := v.(bin.Encoder)
+	if !ok {
+		return 0
+	}
+	var b bin.Buffer
+	if err := e.Encode(&b); err != nil {
+		return 0
+	}
+	return int64(b.Len())
+}
+
+// Middleware returns middleware that counts calls.
+func (s *netStats) Middleware() telegram.Middleware {
+	return telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+		return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+			method := methodName(input)
+			sent := encodedSize(input)
+
+			err := next.Invoke(ctx, input, output)
+
+			var received int64
+			if err == nil {
+				received = encodedSize(output)
+			}
+			s.metrics.Traffic(method, sent, received)
+
+			s.mux.Lock()
+			defer s.mux.Unlock()
+			m, ok := s.methods[method]
+			if !ok {
+				m = &methodStats{}
+				s.methods[method] = m
+			}
+			m.Calls++
+			if err != nil {
+				m.Errors++
+			}
+			m.Sent += sent
+			m.Received += received
+			return err
+		}
+	})
+}
+
+// Print prints total data usage and top methods by traffic.
+func (s *netStats) Print(top int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	type entry struct {
+		method string
+		methodStats
+	}
+	var (
+		entries []entry
+		total   methodStats
+	)
+	for method, m := range s.methods {
+		entries = append(entries, entry{method: method, methodStats: *m})
+		total.Calls += m.Calls
+		total.Errors += m.Errors
+		total.Sent += m.Sent
+		total.Received += m.Received
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Sent+entries[i].Received > entries[j].Sent+entries[j].Received
+	})
+	if len(entries) > top {
+		entries = entries[:top]
+	}
+
+	fmt.Printf("Network: %d calls (%d failed), sent %d bytes, received %d bytes\n",
+		total.Calls, total.Errors, total.Sent, total.Received,
+	)
+	for _, e := range entries {
+		fmt.Printf("  %-40s %6d calls %10d sent %10d received\n", e.method, e.Calls, e.Sent, e.Received)
+	}
+}
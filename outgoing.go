@@ -0,0 +1,80 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+)
+
+// peerSet is set of peers, identified by username or ID.
+type peerSet map[string]struct{}
+
+// parsePeerSet parses comma-separated list of usernames and IDs.
+func parsePeerSet(s string) peerSet {
+	r := peerSet{}
+	for _, v := range strings.Split(s, ",") {
+		v = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(v), "@"))
+		if v == "" {
+			continue
+		}
+		r[v] = struct{}{}
+	}
+	return r
+}
+
+// Contains reports whether given peer is in set.
+func (s peerSet) Contains(p storage.Peer) bool {
+	if _, ok := s[strconv.FormatInt(p.Key.ID, 10)]; ok {
+		return true
+	}
+	var username string
+	switch {
+	case p.User != nil:
+		username = p.User.Username
+	case p.Channel != nil:
+		username = p.Channel.Username
+	}
+	if username == "" {
+		return false
+	}
+	_, ok := s[strings.ToLower(username)]
+	return ok
+}
+
+// outgoingFilter selects outgoing messages to log.
+//
+// Zero value matches all messages.
+type outgoingFilter struct {
+	peers   peerSet
+	pattern *regexp.Regexp
+}
+
+func newOutgoingFilter(peers, pattern string) (outgoingFilter, error) {
+	var f outgoingFilter
+	if peers != "" {
+		f.peers = parsePeerSet(peers)
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return f, errors.Wrap(err, "compile pattern")
+		}
+		f.pattern = re
+	}
+	return f, nil
+}
+
+// Match reports whether outgoing message with given text to given peer
+// should be logged.
+func (f outgoingFilter) Match(p storage.Peer, text string) bool {
+	if f.peers != nil && !f.peers.Contains(p) {
+		return false
+	}
+	if f.pattern != nil && !f.pattern.MatchString(text) {
+		return false
+	}
+	return true
+}
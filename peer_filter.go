@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// peerFilterReport is interval of filtered messages reports.
+const peerFilterReport = time.Minute
+
+// peerFilter selects peers whose messages are handled by allowlist and
+// blocklist of usernames and IDs.
+type peerFilter struct {
+	mux    sync.RWMutex
+	only   peerSet
+	ignore peerSet
+
+	filtered atomic.Int64
+}
+
+func newPeerFilter(only, ignore string) *peerFilter {
+	f := &peerFilter{ignore: parsePeerSet(ignore)}
+	if only != "" {
+		f.only = parsePeerSet(only)
+	}
+	return f
+}
+
+// Resolve resolves usernames of lists to IDs, so peers are matched even if
+// username is changed or not known from update.
+func (f *peerFilter) Resolve(ctx context.Context, resolve func(context.Context, string) (tg.InputPeerClass, error)) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, set := range []peerSet{f.only, f.ignore} {
+		for k := range set {
+			if _, err := strconv.ParseInt(k, 10, 64); err == nil {
+				continue
+			}
+			p, err := resolve(ctx, k)
+			if err != nil {
+				return err
+			}
+			if _, id, ok := inputPeerKey(p); ok {
+				set[strconv.FormatInt(id, 10)] = struct{}{}
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether messages from peer should be handled, counting
+// filtered ones.
+func (f *peerFilter) Match(p storage.Peer) bool {
+	f.mux.RLock()
+	ok := (f.only == nil || f.only.Contains(p)) && !f.ignore.Contains(p)
+	f.mux.RUnlock()
+	if !ok {
+		f.filtered.Add(1)
+	}
+	return ok
+}
+
+// Run periodically logs number of filtered messages until context is done.
+func (f *peerFilter) Run(ctx context.Context, lg *zap.Logger) error {
+	ticker := time.NewTicker(peerFilterReport)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if n := f.filtered.Swap(0); n > 0 {
+			lg.Info("Filtered messages", zap.Int64("count", n), zap.Duration("interval", peerFilterReport))
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// Modes of peer storage migration.
+const (
+	peersMigrateOff       = ""           // only old storage is used
+	peersMigrateDualWrite = "dual-write" // write to both, read from old
+	peersMigrateReadNew   = "read-new"   // write to both, read from new
+)
+
+var _ storage.PeerStorage = (*DualWritePeerStorage)(nil)
+
+// DualWritePeerStorage writes peers to both primary and secondary storage,
+// reading only from primary one.
+//
+// Primary storage is source of truth, so failed write to secondary is only
+// logged and counted, to be fixed by Backfill later.
+type DualWritePeerStorage struct {
+	primary   storage.PeerStorage
+	secondary storage.PeerStorage
+	lg        *zap.Logger
+
+	failed atomic.Int64
+}
+
+// NewDualWritePeerStorage creates new DualWritePeerStorage.
+func NewDualWritePeerStorage(primary, secondary storage.PeerStorage, lg *zap.Logger) *DualWritePeerStorage {
+	return &DualWritePeerStorage{primary: primary, secondary: secondary, lg: lg}
+}
+
+func (s *DualWritePeerStorage) secondaryFailed(err error) {
+	if err == nil {
+		return
+	}
+	s.failed.Add(1)
+	s.lg.Warn("Secondary peer storage write failed", zap.Error(err))
+}
+
+// Failed returns count of failed writes to secondary storage.
+func (s *DualWritePeerStorage) Failed() int64 {
+	return s.failed.Load()
+}
+
+// Add adds given peer to both storages.
+func (s *DualWritePeerStorage) Add(ctx context.Context, value storage.Peer) error {
+	if err := s.primary.Add(ctx, value); err != nil {
+		return err
+	}
+	s.secondaryFailed(s.secondary.Add(ctx, value))
+	return nil
+}
+
+// Find finds peer using given key in primary storage.
+func (s *DualWritePeerStorage) Find(ctx context.Context, key storage.PeerKey) (storage.Peer, error) {
+	return s.primary.Find(ctx, key)
+}
+
+// Assign adds given peer to both storages and associates it to the given key.
+func (s *DualWritePeerStorage) Assign(ctx context.Context, key string, value storage.Peer) error {
+	if err := s.primary.Assign(ctx, key, value); err != nil {
+		return err
+	}
+	s.secondaryFailed(s.secondary.Assign(ctx, key, value))
+	return nil
+}
+
+// Resolve finds peer using associated key in primary storage.
+func (s *DualWritePeerStorage) Resolve(ctx context.Context, key string) (storage.Peer, error) {
+	return s.primary.Resolve(ctx, key)
+}
+
+// Iterate iterates over peers of primary storage.
+func (s *DualWritePeerStorage) Iterate(ctx context.Context) (storage.PeerIterator, error) {
+	return s.primary.Iterate(ctx)
+}
+
+// copyPeers adds all peers of src to dst, returning count of copied peers.
+//
+// Associated keys (usernames) are not enumerable, so they are re-assigned
+// by primary storage on next resolve.
+func copyPeers(ctx context.Context, src, dst storage.PeerStorage) (_ int, rerr error) {
+	iter, err := src.Iterate(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "iterate")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, iter.Close())
+	}()
+
+	n := 0
+	for iter.Next(ctx) {
+		if err := dst.Add(ctx, iter.Value()); err != nil {
+			return n, errors.Wrap(err, "add")
+		}
+		n++
+	}
+	if err := iter.Err(); err != nil {
+		return n, errors.Wrap(err, "next")
+	}
+	return n, nil
+}
+
+// verifyPeers checks that every peer of src is stored in dst with the same
+// value, returning count of checked and mismatched peers.
+func verifyPeers(ctx context.Context, src, dst storage.PeerStorage) (checked, mismatched int, rerr error) {
+	iter, err := src.Iterate(ctx)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "iterate")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, iter.Close())
+	}()
+
+	for iter.Next(ctx) {
+		want := iter.Value()
+		checked++
+		got, err := dst.Find(ctx, storage.KeyFromPeer(want))
+		if errors.Is(err, storage.ErrPeerNotFound) {
+			fmt.Printf("Missing: %s\n", want)
+			mismatched++
+			continue
+		}
+		if err != nil {
+			return checked, mismatched, errors.Wrap(err, "find")
+		}
+		// Comparing ID and access hash only, other fields can differ in
+		// encoding details between backends.
+		if !reflect.DeepEqual(got.AsInputPeer(), want.AsInputPeer()) {
+			fmt.Printf("Mismatch: %s\n", want)
+			mismatched++
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return checked, mismatched, errors.Wrap(err, "next")
+	}
+	return checked, mismatched, nil
+}
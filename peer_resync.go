@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// countingPeerStorage counts peers added to underlying storage.
+type countingPeerStorage struct {
+	storage.PeerStorage
+	added atomic.Int64
+}
+
+func (s *countingPeerStorage) Add(ctx context.Context, value storage.Peer) error {
+	if err := s.PeerStorage.Add(ctx, value); err != nil {
+		return err
+	}
+	s.added.Add(1)
+	return nil
+}
+
+// resyncPeers periodically collects peers from dialogs to keep peer
+// storage up to date with chats appeared after start.
+//
+// Flood waits are handled by waiter middleware of api.
+func resyncPeers(ctx context.Context, lg *zap.Logger, api *tg.Client, peerDB storage.PeerStorage, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		counter := &countingPeerStorage{PeerStorage: peerDB}
+		start := time.Now()
+		if err := storage.CollectPeers(counter).Dialogs(ctx, query.GetDialogs(api).Iter()); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Next cycle can succeed, so not stopping.
+			lg.Warn("Peer re-sync failed", zap.Error(errors.Wrap(err, "collect peers")))
+			continue
+		}
+		lg.Info("Peers re-synced",
+			zap.Int64("peers", counter.added.Load()),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+	"golang.org/x/term"
+)
+
+// pickerLines is maximum number of shown peers.
+const pickerLines = 10
+
+// pickItem is peer that can be picked.
+type pickItem struct {
+	label string
+	peer  tg.InputPeerClass
+}
+
+// collectPickItems fetches dialogs to pick from.
+func collectPickItems(ctx context.Context, api *tg.Client) ([]pickItem, error) {
+	var items []pickItem
+	iter := query.GetDialogs(api).Iter()
+	for iter.Next(ctx) {
+		elem := iter.Value()
+		e, ok := newDialogEntry(elem)
+		if !ok {
+			continue
+		}
+		label := e.Title
+		if e.Username != "" {
+			label += " (@" + e.Username + ")"
+		}
+		items = append(items, pickItem{label: label, peer: elem.Peer})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate dialogs")
+	}
+	return items, nil
+}
+
+// fuzzyScore reports whether all characters of query appear in s in the
+// same order, case-insensitive. Lower score is better match: it is number
+// of skipped characters between first and last matched ones.
+func fuzzyScore(q, s string) (int, bool) {
+	q, s = strings.ToLower(q), strings.ToLower(s)
+	score, start := 0, -1
+	for _, r := range q {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return 0, false
+		}
+		if start >= 0 {
+			score += i
+		}
+		start = i
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return score, true
+}
+
+// filterPickItems returns items matching query, best matches first.
+func filterPickItems(items []pickItem, q string) []pickItem {
+	type scored struct {
+		item  pickItem
+		score int
+	}
+	var matched []scored
+	for _, item := range items {
+		if score, ok := fuzzyScore(q, item.label); ok {
+			matched = append(matched, scored{item: item, score: score})
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].score < matched[j].score
+	})
+	r := make([]pickItem, 0, len(matched))
+	for _, m := range matched {
+		r = append(r, m.item)
+	}
+	return r
+}
+
+// pickPeer prompts user to pick peer from dialogs in terminal, filtering
+// them as user types. Query is initial filter.
+//
+// Stdin should be a terminal.
+func pickPeer(ctx context.Context, api *tg.Client, q string) (tg.InputPeerClass, error) {
+	items, err := collectPickItems(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, errors.Wrap(err, "raw mode")
+	}
+	defer func() {
+		_ = term.Restore(fd, state)
+	}()
+
+	var (
+		r        = bufio.NewReader(os.Stdin)
+		input    = []rune(q)
+		selected = 0
+		matched  = filterPickItems(items, q)
+	)
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if selected >= len(matched) {
+			selected = len(matched) - 1
+		}
+		if selected < 0 {
+			selected = 0
+		}
+		renderPicker(os.Stdout, string(input), matched, selected)
+
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return nil, errors.Wrap(err, "read")
+		}
+		switch c {
+		case 3: // Ctrl+C
+			clearPicker(os.Stdout)
+			return nil, errors.New("peer selection cancelled")
+		case '\r', '\n':
+			if len(matched) == 0 {
+				continue
+			}
+			clearPicker(os.Stdout)
+			fmt.Printf("Selected %s\r\n", matched[selected].label)
+			return matched[selected].peer, nil
+		case 127, 8: // Backspace
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+				matched = filterPickItems(items, string(input))
+			}
+		case 14: // Ctrl+N
+			selected++
+		case 16: // Ctrl+P
+			selected--
+		case 27: // Escape sequence, like arrow keys
+			if r.Buffered() == 0 {
+				clearPicker(os.Stdout)
+				return nil, errors.New("peer selection cancelled")
+			}
+			if next, _ := r.ReadByte(); next != '[' {
+				continue
+			}
+			switch key, _ := r.ReadByte(); key {
+			case 'A':
+				selected--
+			case 'B':
+				selected++
+			}
+		default:
+			if c < ' ' {
+				continue
+			}
+			input = append(input, c)
+			matched = filterPickItems(items, string(input))
+		}
+	}
+}
+
+const pickerPrompt = "Peer: "
+
+// renderPicker draws prompt with matched items below it, leaving cursor at
+// the end of prompt.
+func renderPicker(w io.Writer, q string, matched []pickItem, selected int) {
+	var b strings.Builder
+	b.WriteString("\r\x1b[J" + pickerPrompt + q)
+
+	// Scrolling window of items to keep selected one visible.
+	offset := 0
+	if selected >= pickerLines {
+		offset = selected - pickerLines + 1
+	}
+	lines := 0
+	for i := offset; i < len(matched) && lines < pickerLines; i++ {
+		marker := "  "
+		if i == selected {
+			marker = "> "
+		}
+		b.WriteString("\r\n" + marker + matched[i].label)
+		lines++
+	}
+	if len(matched) == 0 {
+		b.WriteString("\r\n  no matches")
+		lines++
+	}
+	fmt.Fprintf(&b, "\x1b[%dA\r\x1b[%dC", lines, utf8.RuneCountInString(pickerPrompt+q))
+	_, _ = io.WriteString(w, b.String())
+}
+
+// clearPicker removes picker from terminal.
+func clearPicker(w io.Writer) {
+	_, _ = io.WriteString(w, "\r\x1b[J")
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// statsGraph is JSON representation of statistics graph.
+type statsGraph struct {
+	// Columns are series, first element of each column is series key.
+	// Column with "x" key is timestamps in milliseconds.
+	Columns [][]json.RawMessage `json:"columns"`
+	Names   map[string]string   `json:"names"`
+}
+
+// loadGraph loads graph, resolving async graphs.
+func loadGraph(ctx context.Context, api *tg.Client, g tg.StatsGraphClass) (*tg.StatsGraph, error) {
+	for {
+		switch v := g.(type) {
+		case *tg.StatsGraph:
+			return v, nil
+		case *tg.StatsGraphError:
+			return nil, errors.Errorf("graph not available: %s", v.Error)
+		case *tg.StatsGraphAsync:
+			next, err := api.StatsLoadAsyncGraph(ctx, &tg.StatsLoadAsyncGraphRequest{Token: v.Token})
+			if err != nil {
+				return nil, errors.Wrap(err, "load async graph")
+			}
+			g = next
+		default:
+			return nil, errors.Errorf("unexpected graph %T", g)
+		}
+	}
+}
+
+// printGraph prints summary of every series of graph: total and peak.
+func printGraph(g *tg.StatsGraph) error {
+	var data statsGraph
+	if err := json.Unmarshal([]byte(g.JSON.Data), &data); err != nil {
+		return errors.Wrap(err, "unmarshal graph")
+	}
+
+	var x []int64
+	series := map[string][]int64{}
+	var keys []string
+	for _, column := range data.Columns {
+		if len(column) == 0 {
+			continue
+		}
+		var key string
+		if err := json.Unmarshal(column[0], &key); err != nil {
+			return errors.Wrap(err, "unmarshal column key")
+		}
+		values := make([]int64, 0, len(column)-1)
+		for _, raw := range column[1:] {
+			var v int64
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return errors.Wrapf(err, "unmarshal %q value", key)
+			}
+			values = append(values, v)
+		}
+		if key == "x" {
+			x = values
+			continue
+		}
+		series[key] = values
+		keys = append(keys, key)
+	}
+
+	for _, key := range keys {
+		name := data.Names[key]
+		if name == "" {
+			name = key
+		}
+		var total, peak int64
+		var peakAt int
+		for i, v := range series[key] {
+			total += v
+			if v > peak {
+				peak, peakAt = v, i
+			}
+		}
+		fmt.Printf("  %s: total %d", name, total)
+		if peak > 0 && peakAt < len(x) {
+			at := time.UnixMilli(x[peakAt]).Format(time.DateTime)
+			fmt.Printf(", peak %d at %s", peak, at)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// printPostStats prints statistics of given channel post.
+func printPostStats(ctx context.Context, api *tg.Client, p tg.InputPeerClass, msgID int) error {
+	channel, ok := peer.ToInputChannel(p)
+	if !ok {
+		return errors.New("not a channel")
+	}
+
+	msgs, err := getMessages(ctx, api, p, msgID)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return errors.New("post not found")
+	}
+	msg, ok := msgs[0].(*tg.Message)
+	if !ok {
+		return errors.New("post not found")
+	}
+	fmt.Printf("Post %d: %d views, %d forwards\n", msg.ID, msg.Views, msg.Forwards)
+	if r, ok := msg.GetReactions(); ok && len(r.Results) > 0 {
+		fmt.Println("Reactions:")
+		for _, c := range r.Results {
+			switch v := c.Reaction.(type) {
+			case *tg.ReactionEmoji:
+				fmt.Printf("  %s: %d\n", v.Emoticon, c.Count)
+			case *tg.ReactionCustomEmoji:
+				fmt.Printf("  custom emoji %d: %d\n", v.DocumentID, c.Count)
+			}
+		}
+	}
+
+	forwards, err := api.StatsGetMessagePublicForwards(ctx, &tg.StatsGetMessagePublicForwardsRequest{
+		Channel:    channel,
+		MsgID:      msgID,
+		OffsetPeer: &tg.InputPeerEmpty{},
+		Limit:      1,
+	})
+	if err != nil {
+		return postStatsErr(err)
+	}
+	switch f := forwards.(type) {
+	case *tg.MessagesMessagesSlice:
+		fmt.Println("Public forwards:", f.Count)
+	case *tg.MessagesChannelMessages:
+		fmt.Println("Public forwards:", f.Count)
+	default:
+		if m, ok := f.AsModified(); ok {
+			fmt.Println("Public forwards:", len(m.GetMessages()))
+		}
+	}
+
+	stats, err := api.StatsGetMessageStats(ctx, &tg.StatsGetMessageStatsRequest{
+		Channel: channel,
+		MsgID:   msgID,
+	})
+	if err != nil {
+		return postStatsErr(err)
+	}
+	graph, err := loadGraph(ctx, api, stats.ViewsGraph)
+	if err != nil {
+		// Graph is not available for posts with too few views.
+		fmt.Println("Views graph:", err)
+		return nil
+	}
+	fmt.Println("Views graph:")
+	return printGraph(graph)
+}
+
+func postStatsErr(err error) error {
+	switch {
+	case tgerr.Is(err, "CHAT_ADMIN_REQUIRED"):
+		return errors.New("statistics are available only for channel admins")
+	case tgerr.Is(err, "BROADCAST_REQUIRED"):
+		return errors.New("statistics are available only for channels")
+	default:
+		return errors.Wrap(err, "get stats")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tgerr"
+	"rsc.io/qr"
+)
+
+// qrAuth logs in by QR code scanned from another logged in device,
+// prompting for 2FA password if account has one.
+//
+// Login token is accepted via tg.UpdateLoginToken, which is delivered
+// before updates manager is started (see ready channel), because manager
+// passes updates directly to dispatcher until authentication.
+func qrAuth(ctx context.Context, client *telegram.Client, loggedIn qrlogin.LoggedIn, ta terminalAuth) error {
+	_, err := client.QR().Auth(ctx, loggedIn, func(ctx context.Context, token qrlogin.Token) error {
+		// Called again with new token on expiration.
+		fmt.Println("Scan QR code in Telegram app: Settings > Devices > Link Desktop Device")
+		if err := printQR(os.Stdout, token.URL()); err != nil {
+			return err
+		}
+		fmt.Println("Expires at", token.Expires().Format("15:04:05"))
+		return nil
+	})
+	if tgerr.Is(err, "SESSION_PASSWORD_NEEDED") {
+		pwd, err := ta.Password(ctx)
+		if err != nil {
+			return errors.Wrap(err, "get password")
+		}
+		if _, err := client.Auth().Password(ctx, pwd); err != nil {
+			return errors.Wrap(err, "sign in with password")
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "qr login")
+	}
+	return nil
+}
+
+// printQR renders text as QR code using half block characters, so each
+// line of output is two rows of modules.
+//
+// Light modules are printed as blocks, which is expected by scanners on
+// terminals with dark background.
+func printQR(w io.Writer, text string) error {
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return errors.Wrap(err, "encode")
+	}
+	const quiet = 2 // quiet zone around code, in modules
+	light := func(x, y int) bool {
+		return !code.Black(x, y)
+	}
+	var b strings.Builder
+	for y := -quiet; y < code.Size+quiet; y += 2 {
+		for x := -quiet; x < code.Size+quiet; x++ {
+			top, bottom := light(x, y), light(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
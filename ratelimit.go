@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"golang.org/x/time/rate"
+)
+
+// rateLimiter is rate limiting middleware that can fail fast instead of
+// blocking if limit would delay request for too long.
+type rateLimiter struct {
+	lim *rate.Limiter
+	// Maximum delay in nanoseconds, zero means blocking until allowed.
+	maxWait atomic.Int64
+}
+
+func newRateLimiter(r rate.Limit, burst int) *rateLimiter {
+	return &rateLimiter{lim: rate.NewLimiter(r, burst)}
+}
+
+// SetMaxWait sets maximum delay of request, zero means no limit.
+func (r *rateLimiter) SetMaxWait(d time.Duration) {
+	r.maxWait.Store(int64(d))
+}
+
+func (r *rateLimiter) wait(ctx context.Context) error {
+	maxWait := time.Duration(r.maxWait.Load())
+	if maxWait == 0 {
+		return r.lim.Wait(ctx)
+	}
+
+	res := r.lim.Reserve()
+	delay := res.Delay()
+	if delay > maxWait {
+		res.Cancel()
+		return errors.Errorf("rate limit would delay too long (%s > %s)", delay, maxWait)
+	}
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		res.Cancel()
+		return ctx.Err()
+	}
+}
+
+// Middleware returns middleware that limits rate of requests.
+func (r *rateLimiter) Middleware() telegram.Middleware {
+	return telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+		return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+			if err := r.wait(ctx); err != nil {
+				return err
+			}
+			return next.Invoke(ctx, input, output)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// reactionRule is rule of automatic reaction to incoming messages.
+//
+// Empty fields match any message.
+type reactionRule struct {
+	// Peer is username or ID of chat.
+	Peer string `json:"peer"`
+	// Keyword should be contained in message text, case-insensitive.
+	Keyword string `json:"keyword"`
+	// Reaction is emoji to react with, see parseReaction.
+	Reaction string `json:"reaction"`
+
+	peers peerSet
+}
+
+// reactor reacts to incoming messages matching rules.
+type reactor struct {
+	api     *tg.Client
+	lg      *zap.Logger
+	rules   []reactionRule
+	limiter *rate.Limiter
+}
+
+// newReactor creates reactor with given rules, limiting reactions rate.
+func newReactor(api *tg.Client, lg *zap.Logger, rules []reactionRule, limit rate.Limit) (*reactor, error) {
+	for i, r := range rules {
+		if r.Reaction == "" {
+			return nil, errors.Errorf("rule %d: no reaction", i)
+		}
+		if r.Peer != "" {
+			rules[i].peers = parsePeerSet(r.Peer)
+		}
+		rules[i].Keyword = strings.ToLower(r.Keyword)
+	}
+	return &reactor{
+		api:     api,
+		lg:      lg,
+		rules:   rules,
+		limiter: rate.NewLimiter(limit, 1),
+	}, nil
+}
+
+// loadReactionRules loads reaction rules from JSON file, like
+//
+//	[{"peer": "durov", "keyword": "hello", "reaction": "👍"}]
+func loadReactionRules(path string) ([]reactionRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read")
+	}
+	var rules []reactionRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	return rules, nil
+}
+
+func (r *reactor) match(p storage.Peer, text string) (string, bool) {
+	for _, rule := range r.rules {
+		if rule.peers != nil && !rule.peers.Contains(p) {
+			continue
+		}
+		if rule.Keyword != "" && !strings.Contains(strings.ToLower(text), rule.Keyword) {
+			continue
+		}
+		return rule.Reaction, true
+	}
+	return "", false
+}
+
+// Handle reacts to incoming message with reaction of first matching rule.
+func (r *reactor) Handle(ctx context.Context, p storage.Peer, msg *tg.Message) error {
+	if msg.Out {
+		return nil
+	}
+	reaction, ok := r.match(p, msg.Message)
+	if !ok {
+		return nil
+	}
+	if !r.limiter.Allow() {
+		r.lg.Debug("Reaction skipped due to rate limit", zap.Int("msg_id", msg.ID))
+		return nil
+	}
+	sent, err := sendReaction(ctx, r.api, p.AsInputPeer(), msg.ID, reaction)
+	if err != nil {
+		return err
+	}
+	if !sent {
+		r.lg.Info("Reaction is not allowed in chat", zap.Int("msg_id", msg.ID), zap.String("reaction", reaction))
+		return nil
+	}
+	r.lg.Info("Reacted", zap.Int("msg_id", msg.ID), zap.String("reaction", reaction))
+	return nil
+}
+
+// parseReaction parses reaction: emoji or ID of custom emoji document,
+// optionally prefixed by "custom:".
+func parseReaction(s string) tg.ReactionClass {
+	if id, err := strconv.ParseInt(strings.TrimPrefix(s, "custom:"), 10, 64); err == nil {
+		return &tg.ReactionCustomEmoji{DocumentID: id}
+	}
+	return &tg.ReactionEmoji{Emoticon: s}
+}
+
+// sendReaction reacts to message with given reaction, reporting false if
+// reaction is not allowed in chat, e.g. reactions are disabled.
+func sendReaction(ctx context.Context, api *tg.Client, p tg.InputPeerClass, msgID int, reaction string) (bool, error) {
+	if _, err := api.MessagesSendReaction(ctx, &tg.MessagesSendReactionRequest{
+		Peer:     p,
+		MsgID:    msgID,
+		Reaction: []tg.ReactionClass{parseReaction(reaction)},
+	}); err != nil {
+		if tgerr.Is(err, "REACTION_INVALID") {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "send reaction")
+	}
+	return true, nil
+}
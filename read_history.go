@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"go.uber.org/zap"
+)
+
+// readHistory marks messages of peer up to maxID as read, retrying
+// transient errors with exponential backoff at most retries times.
+//
+// Permanent errors (e.g. PEER_ID_INVALID) are logged and not returned, so
+// update handling continues.
+func readHistory(ctx context.Context, lg *zap.Logger, api *tg.Client, p storage.Peer, maxID, retries int) error {
+	read := func() error {
+		var err error
+		if channel, ok := p.AsInputChannel(); ok {
+			_, err = api.ChannelsReadHistory(ctx, &tg.ChannelsReadHistoryRequest{
+				Channel: channel,
+				MaxID:   maxID,
+			})
+		} else {
+			_, err = api.MessagesReadHistory(ctx, &tg.MessagesReadHistoryRequest{
+				Peer:  p.AsInputPeer(),
+				MaxID: maxID,
+			})
+		}
+		if rpcErr, ok := tgerr.As(err); ok && rpcErr.Code >= 400 && rpcErr.Code < 500 {
+			// Request itself is wrong, retrying won't help.
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+	b := backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(retries)), ctx)
+	err := backoff.RetryNotify(read, b, func(err error, wait time.Duration) {
+		lg.Warn("Read history failed, retrying", zap.Error(err), zap.Duration("backoff", wait))
+	})
+	// RetryNotify unwraps permanent error, so checking RPC error itself.
+	if rpcErr, ok := tgerr.As(err); ok && rpcErr.Code >= 400 && rpcErr.Code < 500 {
+		lg.Warn("Read history failed", zap.Int("max_id", maxID), zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "read history")
+	}
+	return nil
+}
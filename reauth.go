@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// selfIDKey is key of ID of account that owns peer database.
+//
+// Key contains "/", so it does not clash with usernames stored by peer
+// storage in the same keyspace.
+var selfIDKey = []byte("meta/self_id")
+
+func storedSelfID(db *pebbledb.DB) (_ int64, rerr error) {
+	data, closer, err := db.Get(selfIDKey)
+	if errors.Is(err, pebbledb.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "get")
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, closer.Close())
+	}()
+	if len(data) != 8 {
+		return 0, errors.Errorf("invalid length %d", len(data))
+	}
+	return int64(binary.BigEndian.Uint64(data)), nil
+}
+
+// wipePeers deletes keys of peer storage, i.e. peers and their usernames
+// and phones.
+//
+// Other keys of database contain "/" and are kept, so send queue and
+// indexes are not lost.
+func wipePeers(db *pebbledb.DB) (rerr error) {
+	iter := db.NewIter(nil)
+	defer func() {
+		multierr.AppendInto(&rerr, iter.Close())
+	}()
+
+	b := db.NewBatch()
+	defer func() {
+		multierr.AppendInto(&rerr, b.Close())
+	}()
+	for iter.First(); iter.Valid(); iter.Next() {
+		if bytes.IndexByte(iter.Key(), '/') >= 0 {
+			continue
+		}
+		if err := b.Delete(iter.Key(), nil); err != nil {
+			return errors.Wrap(err, "delete")
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return errors.Wrap(err, "iter")
+	}
+	return b.Commit(pebbledb.Sync)
+}
+
+// checkAccount verifies that peer database and updates state belong to
+// account with given ID, wiping them otherwise.
+//
+// Peers and state remain valid across re-authentication of the same
+// account, but access hashes and pts of other account are invalid.
+func checkAccount(lg *zap.Logger, db *pebbledb.DB, stateDB *bolt.DB, selfID int64) error {
+	stored, err := storedSelfID(db)
+	if err != nil {
+		return errors.Wrap(err, "stored self id")
+	}
+	if stored != 0 && stored != selfID {
+		lg.Warn("Different account logged in, wiping peers and state",
+			zap.Int64("stored_id", stored),
+			zap.Int64("id", selfID),
+		)
+		fmt.Println("Different account logged in, wiping peers and updates state")
+
+		if err := wipePeers(db); err != nil {
+			return errors.Wrap(err, "wipe peers")
+		}
+		if err := stateDB.Update(func(tx *bolt.Tx) error {
+			var names [][]byte
+			if err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+				names = append(names, append([]byte(nil), name...))
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, name := range names {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "wipe state")
+		}
+	}
+	if stored == selfID {
+		return nil
+	}
+	if err := db.Set(selfIDKey, binary.BigEndian.AppendUint64(nil, uint64(selfID)), pebbledb.Sync); err != nil {
+		return errors.Wrap(err, "set self id")
+	}
+	return nil
+}
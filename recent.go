@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+)
+
+// recentStickersKey is key of cached recent stickers in pebble database.
+var recentStickersKey = []byte("meta/recent_stickers")
+
+// recentStickers returns recently used stickers of account, using cached
+// list if not modified.
+func recentStickers(ctx context.Context, api *tg.Client, db *pebbledb.DB) (*tg.MessagesRecentStickers, error) {
+	cached, err := loadCached[tg.MessagesRecentStickers](db, recentStickersKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "load cache")
+	}
+	res, err := api.MessagesGetRecentStickers(ctx, &tg.MessagesGetRecentStickersRequest{
+		Hash: cached.Hash,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "get recent stickers")
+	}
+	stickers, ok := res.(*tg.MessagesRecentStickers)
+	if !ok {
+		// Not modified, using cache.
+		return cached, nil
+	}
+	if err := storeCached(db, recentStickersKey, stickers); err != nil {
+		return nil, errors.Wrap(err, "save cache")
+	}
+	return stickers, nil
+}
+
+// printRecentStickers prints recently used stickers of account.
+func printRecentStickers(ctx context.Context, api *tg.Client, db *pebbledb.DB) error {
+	stickers, err := recentStickers(ctx, api, db)
+	if err != nil {
+		return err
+	}
+	if len(stickers.Stickers) == 0 {
+		fmt.Println("No recent stickers")
+		return nil
+	}
+	for i, d := range stickers.Stickers {
+		doc, ok := d.AsNotEmpty()
+		if !ok {
+			continue
+		}
+		fmt.Printf("[%d] id=%d", i, doc.ID)
+		for _, a := range doc.Attributes {
+			if a, ok := a.(*tg.DocumentAttributeSticker); ok {
+				fmt.Printf(" %s", a.Alt)
+			}
+		}
+		if i < len(stickers.Dates) {
+			fmt.Printf(" used %s", time.Unix(int64(stickers.Dates[i]), 0).Format(time.DateTime))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// printRecentLocations prints recent geolocations sent to peer.
+//
+// Hash is not cached, because list is per-peer and small.
+func printRecentLocations(ctx context.Context, api *tg.Client, p tg.InputPeerClass) error {
+	res, err := api.MessagesGetRecentLocations(ctx, &tg.MessagesGetRecentLocationsRequest{
+		Peer:  p,
+		Limit: 100,
+	})
+	if err != nil {
+		return errors.Wrap(err, "get recent locations")
+	}
+	modified, ok := res.AsModified()
+	if !ok || len(modified.GetMessages()) == 0 {
+		fmt.Println("No recent locations")
+		return nil
+	}
+	for _, m := range modified.GetMessages() {
+		msg, ok := m.(*tg.Message)
+		if !ok {
+			continue
+		}
+		date := time.Unix(int64(msg.Date), 0).Format(time.DateTime)
+		switch media := msg.Media.(type) {
+		case *tg.MessageMediaGeo:
+			printGeo(msg.ID, date, "", media.Geo)
+		case *tg.MessageMediaGeoLive:
+			printGeo(msg.ID, date, " (live)", media.Geo)
+		case *tg.MessageMediaVenue:
+			printGeo(msg.ID, date, " "+media.Title, media.Geo)
+		}
+	}
+	return nil
+}
+
+func printGeo(msgID int, date, suffix string, geo tg.GeoPointClass) {
+	point, ok := geo.AsNotEmpty()
+	if !ok {
+		return
+	}
+	fmt.Printf("  [%d] %s: %.6f,%.6f%s\n", msgID, date, point.Lat, point.Long, suffix)
+}
+
+// printRecent prints recent items of given kind.
+func printRecent(ctx context.Context, api *tg.Client, db *pebbledb.DB, kind string, resolve func() (tg.InputPeerClass, error)) error {
+	switch kind {
+	case "stickers":
+		return printRecentStickers(ctx, api, db)
+	case "gifs":
+		// There is no separate list of recent GIFs, saved GIFs are
+		// ordered by recent usage.
+		return printSavedGifs(ctx, api, db)
+	case "locations":
+		p, err := resolve()
+		if err != nil {
+			return err
+		}
+		return printRecentLocations(ctx, api, p)
+	default:
+		return errors.Errorf("unknown recent items %q, expected stickers, gifs or locations", kind)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// recoverHandler wraps dispatcher handler, converting panic to error.
+//
+// A single malformed update should not crash the whole program, so panic is
+// logged with stack trace and returned as handler error, which is handled
+// as any other handler error (see -strict-updates).
+func recoverHandler[U tg.UpdateClass](lg *zap.Logger, h func(context.Context, tg.Entities, U) error) func(context.Context, tg.Entities, U) error {
+	return func(ctx context.Context, e tg.Entities, u U) (rerr error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			fields := []zap.Field{
+				zap.String("update", fmt.Sprintf("%T", u)),
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			}
+			if m, ok := any(u).(interface{ GetMessage() tg.MessageClass }); ok {
+				// Message itself can be the cause of panic, so checking
+				// it carefully.
+				if msg, ok := m.GetMessage().(interface {
+					GetID() int
+					GetPeerID() tg.PeerClass
+				}); ok && msg != nil {
+					fields = append(fields,
+						zap.Int("msg_id", msg.GetID()),
+						zap.Any("peer", msg.GetPeerID()),
+					)
+				}
+			}
+			lg.Error("Handler panic", fields...)
+			rerr = errors.Errorf("handler panic: %v", r)
+		}()
+		return h(ctx, e, u)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+const replUsage = "Commands: send <peer> <text>, resolve <username>, dialogs, self, exit"
+
+// repl is interactive shell of few API calls, sharing client and caches
+// with the rest of example.
+type repl struct {
+	lg        *zap.Logger
+	api       *tg.Client
+	peerDB    storage.PeerStorage
+	sender    *message.Sender
+	parseMode string
+	resolve   func(context.Context, string) (tg.InputPeerClass, error)
+	self      *tg.User
+	selfFull  *selfInfo
+	dc        int
+}
+
+// Run reads commands from in until EOF, exit command or context is done.
+func (r *repl) Run(ctx context.Context, in io.Reader) error {
+	// Reading in separate goroutine to not block on cancellation.
+	lines := make(chan string)
+	errs := make(chan error, 1)
+	go func() {
+		s := bufio.NewScanner(in)
+		for s.Scan() {
+			select {
+			case lines <- s.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		errs <- s.Err()
+	}()
+
+	fmt.Println(replUsage)
+	for {
+		fmt.Print("> ")
+		var line string
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return errors.Wrap(err, "read")
+		case line = <-lines:
+		}
+		if strings.TrimSpace(line) == "exit" {
+			return nil
+		}
+		if err := r.exec(ctx, line); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Not stopping shell on failed command.
+			fmt.Println("Error:", err)
+		}
+	}
+}
+
+func (r *repl) exec(ctx context.Context, line string) error {
+	cmd, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	args = strings.TrimSpace(args)
+	switch cmd {
+	case "":
+		return nil
+	case "send":
+		to, text, _ := strings.Cut(args, " ")
+		if to == "" || strings.TrimSpace(text) == "" {
+			return errors.New("usage: send <peer> <text>")
+		}
+		p, err := resolveRecipient(ctx, r.resolve, to)
+		if err != nil {
+			return err
+		}
+		if r.parseMode != "" {
+			return sendStyledText(ctx, r.sender, r.peerDB, p, 0, r.parseMode, text)
+		}
+		return sendText(ctx, r.api, p, 0, text)
+	case "resolve":
+		if args == "" {
+			return errors.New("usage: resolve <username>")
+		}
+		p, err := resolveRecipient(ctx, r.resolve, args)
+		if err != nil {
+			return err
+		}
+		if kind, id, ok := inputPeerKey(p); ok {
+			fmt.Printf("%s %d\n", kind, id)
+			return nil
+		}
+		fmt.Println(p)
+		return nil
+	case "dialogs":
+		return printDialogs(ctx, r.api, r.peerDB)
+	case "self":
+		fmt.Printf("%s (id %d)\n", userName(r.self), r.self.ID)
+		return printSelf(ctx, r.lg, r.api, r.selfFull, r.self, r.dc)
+	default:
+		fmt.Println(replUsage)
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/telegram/query/dialogs"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// resolvePeer resolves peer from command line argument.
+//
+// Numeric IDs are looked up only in peer storage, because access hash
+// can't be obtained from ID alone. Everything else (@username, t.me links,
+// phone numbers) is resolved via resolver.
+func resolvePeer(ctx context.Context, resolver peer.Resolver, peerDB storage.PeerStorage, from string) (tg.InputPeerClass, error) {
+	if id, err := strconv.ParseInt(from, 10, 64); err == nil {
+		for _, kind := range []dialogs.PeerKind{dialogs.User, dialogs.Channel, dialogs.Chat} {
+			p, err := peerDB.Find(ctx, storage.PeerKey{Kind: kind, ID: id})
+			if errors.Is(err, storage.ErrPeerNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, "find peer")
+			}
+			return p.AsInputPeer(), nil
+		}
+		return nil, errors.Errorf("peer %d not found in storage (try -fill-peer-storage)", id)
+	}
+
+	p, err := peer.Resolve(resolver, from)(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolve %q", from)
+	}
+	return p, nil
+}
+
+// apiResolver logs resolves that missed resolver cache and are made via
+// API, so reuse of cached access hashes is observable.
+type apiResolver struct {
+	next peer.Resolver
+	lg   *zap.Logger
+}
+
+// ResolveDomain implements peer.Resolver.
+func (r apiResolver) ResolveDomain(ctx context.Context, domain string) (tg.InputPeerClass, error) {
+	r.lg.Debug("Resolver cache miss", zap.String("domain", domain))
+	return r.next.ResolveDomain(ctx, domain)
+}
+
+// ResolvePhone implements peer.Resolver.
+func (r apiResolver) ResolvePhone(ctx context.Context, phone string) (tg.InputPeerClass, error) {
+	r.lg.Debug("Resolver cache miss", zap.String("phone", phone))
+	return r.next.ResolvePhone(ctx, phone)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/tg"
+	"go.uber.org/multierr"
+)
+
+// savedGifsKey is key of cached saved GIFs in pebble database.
+var savedGifsKey = []byte("meta/saved_gifs")
+
+// savedGifs returns saved GIFs of account.
+//
+// List is cached in database, and cached hash is sent to server, so list
+// is transferred only if changed.
+func savedGifs(ctx context.Context, api *tg.Client, db *pebbledb.DB) ([]*tg.Document, error) {
+	cached, err := loadCached[tg.MessagesSavedGifs](db, savedGifsKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "load cache")
+	}
+	res, err := api.MessagesGetSavedGifs(ctx, cached.Hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "get saved gifs")
+	}
+
+	gifs, ok := res.(*tg.MessagesSavedGifs)
+	if !ok {
+		// Not modified, using cache.
+		gifs = cached
+	} else {
+		if err := storeCached(db, savedGifsKey, gifs); err != nil {
+			return nil, errors.Wrap(err, "save cache")
+		}
+	}
+
+	var r []*tg.Document
+	for _, d := range gifs.Gifs {
+		if doc, ok := d.AsNotEmpty(); ok {
+			r = append(r, doc)
+		}
+	}
+	return r, nil
+}
+
+// loadCached decodes cached API result from database, returning empty
+// result if there is no valid cache.
+func loadCached[T any, PT interface {
+	*T
+	bin.Decoder
+}](db *pebbledb.DB, key []byte) (_ PT, rerr error) {
+	data, closer, err := db.Get(key)
+	if errors.Is(err, pebbledb.ErrNotFound) {
+		return new(T), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		multierr.AppendInto(&rerr, closer.Close())
+	}()
+
+	v := PT(new(T))
+	if err := v.Decode(&bin.Buffer{Buf: data}); err != nil {
+		// Invalid cache is not fatal, full result will be fetched.
+		return new(T), nil
+	}
+	return v, nil
+}
+
+// storeCached saves API result to database.
+func storeCached(db *pebbledb.DB, key []byte, v bin.Encoder) error {
+	var b bin.Buffer
+	if err := v.Encode(&b); err != nil {
+		return errors.Wrap(err, "encode")
+	}
+	return db.Set(key, b.Raw(), nil)
+}
+
+// printSavedGifs prints saved GIFs of account.
+func printSavedGifs(ctx context.Context, api *tg.Client, db *pebbledb.DB) error {
+	gifs, err := savedGifs(ctx, api, db)
+	if err != nil {
+		return err
+	}
+	if len(gifs) == 0 {
+		fmt.Println("No saved GIFs")
+		return nil
+	}
+	for i, doc := range gifs {
+		fmt.Printf("[%d] id=%d size=%d", i, doc.ID, doc.Size)
+		for _, a := range doc.Attributes {
+			switch a := a.(type) {
+			case *tg.DocumentAttributeVideo:
+				fmt.Printf(" %dx%d %ds", a.W, a.H, a.Duration)
+			case *tg.DocumentAttributeFilename:
+				fmt.Printf(" %s", a.FileName)
+			}
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// sendSavedGif sends saved GIF with given index to peer.
+func sendSavedGif(ctx context.Context, api *tg.Client, db *pebbledb.DB, sender *message.Sender, p tg.InputPeerClass, index int) error {
+	gifs, err := savedGifs(ctx, api, db)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(gifs) {
+		return errors.Errorf("invalid index %d, have %d saved GIFs", index, len(gifs))
+	}
+	if _, err := sender.To(p).Media(ctx, message.Document(gifs[index])); err != nil {
+		return errors.Wrap(err, "send gif")
+	}
+	fmt.Println("GIF sent")
+	return nil
+}
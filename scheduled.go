@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+)
+
+// printScheduled prints messages scheduled in dialog with given peer.
+//
+// Date of scheduled message is time it will be sent at.
+func printScheduled(ctx context.Context, api *tg.Client, p tg.InputPeerClass) error {
+	res, err := api.MessagesGetScheduledHistory(ctx, &tg.MessagesGetScheduledHistoryRequest{
+		Peer: p,
+	})
+	if err != nil {
+		return errors.Wrap(err, "get scheduled history")
+	}
+	printMessages("Scheduled messages", res)
+	return nil
+}
+
+// cancelScheduled deletes scheduled message from dialog with given peer.
+func cancelScheduled(ctx context.Context, api *tg.Client, p tg.InputPeerClass, msgID int) error {
+	if _, err := api.MessagesDeleteScheduledMessages(ctx, &tg.MessagesDeleteScheduledMessagesRequest{
+		Peer: p,
+		ID:   []int{msgID},
+	}); err != nil {
+		return errors.Wrap(err, "delete scheduled messages")
+	}
+	fmt.Printf("Scheduled message %d cancelled\n", msgID)
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// selfInfo caches full info of current user, so it is requested only once
+// per process, even if client is restarted.
+type selfInfo struct {
+	mux  sync.Mutex
+	full *tg.UserFull
+}
+
+// Get returns full info of current user.
+func (s *selfInfo) Get(ctx context.Context, api *tg.Client) (*tg.UserFull, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.full != nil {
+		return s.full, nil
+	}
+	full, err := api.UsersGetFullUser(ctx, &tg.InputUserSelf{})
+	if err != nil {
+		return nil, errors.Wrap(err, "get full user")
+	}
+	s.full = &full.FullUser
+	return s.full, nil
+}
+
+// printSelf prints extended summary of current user: premium status,
+// phone, bio and DC of connection.
+func printSelf(ctx context.Context, lg *zap.Logger, api *tg.Client, info *selfInfo, self *tg.User, dc int) error {
+	full, err := info.Get(ctx, api)
+	if err != nil {
+		return err
+	}
+	about, _ := full.GetAbout()
+	phone, _ := self.GetPhone()
+	if phone != "" {
+		phone = "+" + phone
+	}
+
+	fmt.Println("Premium:", self.Premium)
+	fmt.Println("Phone:", phone)
+	fmt.Println("Bio:", about)
+	fmt.Println("DC:", dc)
+	lg.Info("Self",
+		zap.Int64("id", self.ID),
+		zap.Bool("premium", self.Premium),
+		zap.Bool("bot", self.Bot),
+		zap.String("phone", phone),
+		zap.String("bio", about),
+		zap.Int("dc", dc),
+	)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// resolveRecipient resolves peer to send to, "me" is saved messages.
+func resolveRecipient(ctx context.Context, resolve func(context.Context, string) (tg.InputPeerClass, error), to string) (tg.InputPeerClass, error) {
+	if to == "me" {
+		return &tg.InputPeerSelf{}, nil
+	}
+	p, err := resolve(ctx, to)
+	switch {
+	case err == nil:
+		return p, nil
+	case tgerr.Is(err, "USERNAME_NOT_OCCUPIED", "PHONE_NOT_OCCUPIED"):
+		return nil, errors.Errorf("%s not found", to)
+	case tgerr.Is(err, "USERNAME_INVALID", "PHONE_NUMBER_INVALID"):
+		return nil, errors.Errorf("%s is invalid username or phone", to)
+	default:
+		return nil, err
+	}
+}
+
+// sendTarget resolves peer to send to and ID of message to reply to. If
+// commentOn is set, message is sent as comment to post with given ID, i.e.
+// to linked discussion group as reply to copy of post.
+func sendTarget(ctx context.Context, api *tg.Client, resolve func(context.Context, string) (tg.InputPeerClass, error), to string, commentOn int) (tg.InputPeerClass, int, error) {
+	if to == "" {
+		return nil, 0, errors.New("no -to peer")
+	}
+	p, err := resolveRecipient(ctx, resolve, to)
+	if err != nil {
+		return nil, 0, err
+	}
+	if commentOn == 0 {
+		return p, 0, nil
+	}
+	return discussion(ctx, api, p, commentOn)
+}
+
+// sendStyledText sends text message to peer, parsed with given parse
+// mode, as reply to given message if replyTo is not zero.
+func sendStyledText(ctx context.Context, sender *message.Sender, peerDB storage.PeerStorage, p tg.InputPeerClass, replyTo int, mode, text string) error {
+	opts, err := parseText(ctx, peerDB, mode, text)
+	if err != nil {
+		return errors.Wrap(err, "parse")
+	}
+	if _, err := sender.To(p).Reply(replyTo).StyledText(ctx, opts...); err != nil {
+		return errors.Wrap(err, "send message")
+	}
+	fmt.Println("Message sent")
+	return nil
+}
+
+// sendText sends text message to peer, as reply to given message if
+// replyTo is not zero.
+func sendText(ctx context.Context, api *tg.Client, p tg.InputPeerClass, replyTo int, text string) error {
+	var randomID int64
+	if err := binary.Read(rand.Reader, binary.LittleEndian, &randomID); err != nil {
+		return errors.Wrap(err, "random id")
+	}
+	if _, err := api.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
+		Peer:         p,
+		Message:      text,
+		RandomID:     randomID,
+		ReplyToMsgID: replyTo,
+	}); err != nil {
+		return errors.Wrap(err, "send message")
+	}
+	fmt.Println("Message sent")
+	return nil
+}
+
+// maxMediaTTL is maximum self-destruct timer of media in seconds.
+const maxMediaTTL = 60
+
+// sendFile uploads file from path and sends it to peer, as photo for
+// images and as document otherwise, as reply to given message if replyTo
+// is not zero. Media self-destructs after ttl seconds if ttl is positive.
+func sendFile(ctx context.Context, sender *message.Sender, p tg.InputPeerClass, replyTo int, path string, ttl int) error {
+	f, err := sender.To(p).Upload(message.FromPath(path)).AsInputFile(ctx)
+	if err != nil {
+		return errors.Wrap(err, "upload")
+	}
+
+	var media message.MediaOption
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/webp":
+		photo := message.UploadedPhoto(f)
+		if ttl > 0 {
+			photo = photo.TTLSeconds(ttl)
+		}
+		media = photo
+	default:
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		// Strip parameters like charset.
+		mimeType, _, _ = strings.Cut(mimeType, ";")
+		doc := message.UploadedDocument(f).
+			Filename(filepath.Base(path)).
+			MIME(mimeType)
+		if ttl > 0 {
+			doc = doc.TTLSeconds(ttl)
+		}
+		media = doc
+	}
+	if _, err := sender.To(p).Reply(replyTo).Media(ctx, media); err != nil {
+		return errors.Wrap(err, "send media")
+	}
+	fmt.Println("File sent")
+	return nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// sendQueuePrefix is key prefix of send queue entries in pebble database.
+var sendQueuePrefix = []byte("send_queue/")
+
+// queuedMessage is message in send queue.
+type queuedMessage struct {
+	Peer string `json:"peer"`
+	Text string `json:"text"`
+	// RandomID is generated on enqueue, so message that was sent before
+	// crash but not removed from queue is deduplicated by server.
+	RandomID int64     `json:"random_id"`
+	Enqueued time.Time `json:"enqueued"`
+}
+
+// Bounds of delay between attempts to send message that failed with
+// transient error, delay is doubled on every failed attempt.
+const (
+	sendQueueMinBackoff = time.Second
+	sendQueueMaxBackoff = 5 * time.Minute
+)
+
+// SendQueue is persistent queue of messages to send, stored in pebble
+// database.
+//
+// Messages are removed only after successful sending or permanent error,
+// so unsent messages are retried after restart.
+type SendQueue struct {
+	db      *pebbledb.DB
+	lg      *zap.Logger
+	limiter *rate.Limiter
+	// name is name of session, used as label of queue depth metric.
+	name string
+	seq  atomic.Uint64
+}
+
+// NewSendQueue creates new SendQueue.
+func NewSendQueue(db *pebbledb.DB, lg *zap.Logger, name string, limit rate.Limit) *SendQueue {
+	return &SendQueue{
+		db:      db,
+		lg:      lg,
+		limiter: rate.NewLimiter(limit, 1),
+		name:    name,
+	}
+}
+
+// Enqueue adds message to queue.
+func (q *SendQueue) Enqueue(peer, text string) error {
+	var randomID int64
+	if err := binary.Read(rand.Reader, binary.LittleEndian, &randomID); err != nil {
+		return errors.Wrap(err, "random id")
+	}
+	data, err := json.Marshal(queuedMessage{
+		Peer:     peer,
+		Text:     text,
+		RandomID: randomID,
+		Enqueued: time.Now(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	if err := q.db.Set(q.key(), data, pebbledb.Sync); err != nil {
+		return errors.Wrap(err, "set")
+	}
+	return nil
+}
+
+// key returns key of message added to tail of queue.
+//
+// Keys are ordered by enqueue time, so queue is FIFO. Sequence number
+// makes keys of messages enqueued at the same time unique.
+func (q *SendQueue) key() []byte {
+	k := append([]byte(nil), sendQueuePrefix...)
+	k = binary.BigEndian.AppendUint64(k, uint64(time.Now().UnixNano()))
+	return binary.BigEndian.AppendUint64(k, q.seq.Add(1))
+}
+
+func (q *SendQueue) iter() *pebbledb.Iterator {
+	return q.db.NewIter(&pebbledb.IterOptions{
+		LowerBound: sendQueuePrefix,
+		UpperBound: append(append([]byte(nil), sendQueuePrefix...), 0xff),
+	})
+}
+
+// Len returns number of queued messages.
+func (q *SendQueue) Len() (_ int, rerr error) {
+	iter := q.iter()
+	defer func() {
+		multierr.AppendInto(&rerr, iter.Close())
+	}()
+	n := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		n++
+	}
+	return n, nil
+}
+
+// peek returns first message of queue and its key.
+func (q *SendQueue) peek() (_ []byte, _ queuedMessage, _ bool, rerr error) {
+	iter := q.iter()
+	defer func() {
+		multierr.AppendInto(&rerr, iter.Close())
+	}()
+	if !iter.First() {
+		return nil, queuedMessage{}, false, nil
+	}
+	var m queuedMessage
+	key := append([]byte(nil), iter.Key()...)
+	if err := json.Unmarshal(iter.Value(), &m); err != nil {
+		return key, m, false, errors.Wrap(err, "unmarshal")
+	}
+	return key, m, true, nil
+}
+
+// permanentSendErr reports whether sending of message will never succeed.
+func permanentSendErr(err error) bool {
+	rpcErr, ok := tgerr.As(err)
+	if !ok {
+		return false
+	}
+	if rpcErr.IsType("RANDOM_ID_DUPLICATE") {
+		return false
+	}
+	return rpcErr.Code == 400 || rpcErr.Code == 403
+}
+
+// reportDepth logs and exports number of queued messages.
+func (q *SendQueue) reportDepth() {
+	n, err := q.Len()
+	if err != nil {
+		q.lg.Warn("Send queue length", zap.Error(err))
+		return
+	}
+	getMetrics().SendQueueDepth(q.name, n)
+	q.lg.Info("Send queue", zap.Int("depth", n))
+}
+
+// Run drains queue until context is done.
+//
+// Message that failed with transient error stays at head of queue and is
+// retried with exponential backoff, so network outage does not lose
+// messages.
+func (q *SendQueue) Run(ctx context.Context, api *tg.Client, resolve func(ctx context.Context, s string) (tg.InputPeerClass, error)) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	q.reportDepth()
+	backoff := sendQueueMinBackoff
+	for {
+		key, m, ok, err := q.peek()
+		if err != nil {
+			// Corrupted entry should not block queue.
+			q.lg.Error("Invalid send queue entry, removing", zap.Error(err))
+			if err := q.db.Delete(key, pebbledb.Sync); err != nil {
+				return errors.Wrap(err, "delete")
+			}
+			continue
+		}
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				continue
+			}
+		}
+
+		if err := q.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		err = q.send(ctx, api, resolve, m)
+		switch {
+		case err == nil:
+			backoff = sendQueueMinBackoff
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case permanentSendErr(err):
+			q.lg.Error("Dropping queued message", zap.String("peer", m.Peer), zap.Error(err))
+		default:
+			q.lg.Warn("Send queued message, retrying",
+				zap.String("peer", m.Peer),
+				zap.Duration("backoff", backoff),
+				zap.Error(err),
+			)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > sendQueueMaxBackoff {
+				backoff = sendQueueMaxBackoff
+			}
+			continue
+		}
+		if err := q.db.Delete(key, pebbledb.Sync); err != nil {
+			return errors.Wrap(err, "delete")
+		}
+		q.reportDepth()
+	}
+}
+
+func (q *SendQueue) send(ctx context.Context, api *tg.Client, resolve func(ctx context.Context, s string) (tg.InputPeerClass, error), m queuedMessage) error {
+	p, err := resolve(ctx, m.Peer)
+	if err != nil {
+		return errors.Wrap(err, "resolve")
+	}
+	if _, err := api.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
+		Peer:     p,
+		Message:  m.Text,
+		RandomID: m.RandomID,
+	}); err != nil {
+		if tgerr.Is(err, "RANDOM_ID_DUPLICATE") {
+			// Already sent before restart.
+			return nil
+		}
+		return errors.Wrap(err, "send")
+	}
+	fmt.Printf("Sent queued message to %s\n", m.Peer)
+	return nil
+}
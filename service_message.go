@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// entityUserName returns name of user from entities or its ID if user is
+// not present.
+func entityUserName(e tg.Entities, id int64) string {
+	u, ok := e.Users[id]
+	if !ok {
+		return fmt.Sprintf("user %d", id)
+	}
+	return userName(u)
+}
+
+// userName returns full name of user with username, if any.
+func userName(u *tg.User) string {
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if u.Username != "" {
+		name += " (@" + u.Username + ")"
+	}
+	return name
+}
+
+// describeAction returns human-readable description of service message
+// action, done by actor.
+func describeAction(e tg.Entities, actor string, action tg.MessageActionClass, replyTo int) string {
+	users := func(ids []int64) string {
+		names := make([]string, 0, len(ids))
+		for _, id := range ids {
+			names = append(names, entityUserName(e, id))
+		}
+		return strings.Join(names, ", ")
+	}
+	switch a := action.(type) {
+	case *tg.MessageActionChatCreate:
+		return fmt.Sprintf("%s created group %q", actor, a.Title)
+	case *tg.MessageActionChannelCreate:
+		return fmt.Sprintf("%s created channel %q", actor, a.Title)
+	case *tg.MessageActionChatEditTitle:
+		return fmt.Sprintf("%s changed title to %q", actor, a.Title)
+	case *tg.MessageActionChatEditPhoto:
+		return fmt.Sprintf("%s changed photo", actor)
+	case *tg.MessageActionChatDeletePhoto:
+		return fmt.Sprintf("%s removed photo", actor)
+	case *tg.MessageActionChatAddUser:
+		return fmt.Sprintf("%s added %s", actor, users(a.Users))
+	case *tg.MessageActionChatDeleteUser:
+		return fmt.Sprintf("%s removed %s", actor, entityUserName(e, a.UserID))
+	case *tg.MessageActionChatJoinedByLink:
+		return fmt.Sprintf("%s joined by invite link of %s", actor, entityUserName(e, a.InviterID))
+	case *tg.MessageActionChatJoinedByRequest:
+		return fmt.Sprintf("%s joined by approved request", actor)
+	case *tg.MessageActionChatMigrateTo:
+		return fmt.Sprintf("%s upgraded group to supergroup %d", actor, a.ChannelID)
+	case *tg.MessageActionChannelMigrateFrom:
+		return fmt.Sprintf("Supergroup was upgraded from group %q", a.Title)
+	case *tg.MessageActionPinMessage:
+		return fmt.Sprintf("%s pinned message %d", actor, replyTo)
+	case *tg.MessageActionHistoryClear:
+		return "History was cleared"
+	case *tg.MessageActionSetMessagesTTL:
+		if a.Period == 0 {
+			return fmt.Sprintf("%s disabled auto-delete timer", actor)
+		}
+		return fmt.Sprintf("%s set auto-delete timer to %ds", actor, a.Period)
+	case *tg.MessageActionTopicCreate:
+		return fmt.Sprintf("%s created topic %q", actor, a.Title)
+	case *tg.MessageActionGroupCall:
+		if _, ok := a.GetDuration(); ok {
+			return fmt.Sprintf("%s ended video chat", actor)
+		}
+		return fmt.Sprintf("%s started video chat", actor)
+	default:
+		return fmt.Sprintf("%s: %s", actor, action.TypeName())
+	}
+}
+
+// printServiceMessage prints description of service message action.
+func printServiceMessage(ctx context.Context, lg *zap.Logger, peerDB storage.PeerStorage, e tg.Entities, svc *tg.MessageService, prefix string) {
+	p, err := storage.FindPeer(ctx, peerDB, svc.PeerID)
+	if err != nil {
+		lg.Warn("Find peer of service message", zap.Error(err))
+		return
+	}
+	actor := "Channel"
+	if from, ok := svc.GetFromID(); ok {
+		if u, ok := from.(*tg.PeerUser); ok {
+			actor = entityUserName(e, u.UserID)
+		}
+	} else if u, ok := svc.PeerID.(*tg.PeerUser); ok {
+		// Private chat.
+		actor = entityUserName(e, u.UserID)
+	}
+	var replyTo int
+	if r, ok := svc.GetReplyTo(); ok {
+		replyTo = r.ReplyToMsgID
+	}
+	text := describeAction(e, actor, svc.Action, replyTo)
+
+	lg.Info("Service message",
+		zap.Int("msg_id", svc.ID),
+		zap.String("action", svc.Action.TypeName()),
+		zap.String("text", text),
+	)
+	fmt.Printf("%s%s: %s\n", prefix, p, text)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/session"
+)
+
+// exportSession prints session from storage as portable base64 string.
+func exportSession(ctx context.Context, storage session.Storage) error {
+	data, err := (&session.Loader{Storage: storage}).Load(ctx)
+	if errors.Is(err, session.ErrNotFound) {
+		return errors.New("no session, log in first")
+	}
+	if err != nil {
+		return errors.Wrap(err, "load")
+	}
+	var mem session.StorageMemory
+	if err := (&session.Loader{Storage: &mem}).Save(ctx, data); err != nil {
+		return errors.Wrap(err, "encode")
+	}
+	raw, err := mem.Bytes(nil)
+	if err != nil {
+		return errors.Wrap(err, "encode")
+	}
+	fmt.Println(base64.RawURLEncoding.EncodeToString(raw))
+	return nil
+}
+
+// decodeSession decodes session string exported by exportSession or
+// Telethon's StringSession.
+func decodeSession(ctx context.Context, s string) (*session.Data, error) {
+	data, err := decodeSessionString(ctx, s)
+	if err == nil {
+		return data, nil
+	}
+	if data, telethonErr := session.TelethonSession(s); telethonErr == nil {
+		return data, nil
+	}
+	return nil, err
+}
+
+func decodeSessionString(ctx context.Context, s string) (*session.Data, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode")
+	}
+	mem := new(session.StorageMemory)
+	if err := mem.StoreSession(ctx, raw); err != nil {
+		return nil, err
+	}
+	return (&session.Loader{Storage: mem}).Load(ctx)
+}
+
+// importSession reads session string from r and saves it to file storage,
+// which is overwritten only if force is set.
+func importSession(ctx context.Context, r io.Reader, storage *session.FileStorage, force bool) error {
+	if _, err := os.Stat(storage.Path); err == nil && !force {
+		return errors.Errorf("session %s already exists, use -force to overwrite", storage.Path)
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return errors.Wrap(err, "read")
+	}
+	data, err := decodeSession(ctx, strings.TrimSpace(line))
+	if err != nil {
+		return errors.Wrap(err, "invalid session")
+	}
+	if err := (&session.Loader{Storage: storage}).Save(ctx, data); err != nil {
+		return errors.Wrap(err, "save")
+	}
+	fmt.Println("Session imported to", storage.Path)
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// runWithShutdownTimeout runs f and, after ctx is done, waits at most
+// timeout for f to return.
+//
+// On timeout process exits with code 0 right away: f is still running and
+// may use databases, so they can't be closed by deferred calls of caller.
+// Pebble and bolt recover from unclean exit, only writes that were not
+// synced can be lost.
+func runWithShutdownTimeout(ctx context.Context, lg *zap.Logger, timeout time.Duration, f func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		lg.Info("Shutdown is clean")
+		return err
+	case <-timer.C:
+		lg.Warn("Shutdown timed out, exiting without waiting for handlers", zap.Duration("timeout", timeout))
+		_ = lg.Sync()
+		fmt.Printf("\rShutdown timed out after %s\n", timeout)
+		os.Exit(0)
+		return nil
+	}
+}
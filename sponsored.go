@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/tg"
+)
+
+// printSponsored prints sponsored messages of given channel, optionally
+// marking them as viewed.
+func printSponsored(ctx context.Context, api *tg.Client, p tg.InputPeerClass, view bool) error {
+	channel, ok := peer.ToInputChannel(p)
+	if !ok {
+		return errors.New("not a channel")
+	}
+	res, err := api.ChannelsGetSponsoredMessages(ctx, channel)
+	if err != nil {
+		return errors.Wrap(err, "get sponsored messages")
+	}
+	sponsored, ok := res.(*tg.MessagesSponsoredMessages)
+	if !ok || len(sponsored.Messages) == 0 {
+		fmt.Println("No sponsored messages")
+		return nil
+	}
+
+	entities := peer.EntitiesFromResult(sponsored)
+	for _, m := range sponsored.Messages {
+		from := "unknown"
+		switch f := m.FromID.(type) {
+		case *tg.PeerUser:
+			from = fmt.Sprintf("user %d", f.UserID)
+			if u, ok := entities.User(f.UserID); ok {
+				from = u.FirstName
+			}
+		case *tg.PeerChannel:
+			from = fmt.Sprintf("channel %d", f.ChannelID)
+			if c, ok := entities.Channel(f.ChannelID); ok {
+				from = c.Title
+			}
+		}
+		if invite, ok := m.ChatInvite.(*tg.ChatInvite); ok {
+			from = invite.Title + " (invite)"
+		}
+
+		fmt.Printf("Sponsor: %s\n", from)
+		if m.SponsorInfo != "" {
+			fmt.Println("Info:", m.SponsorInfo)
+		}
+		if m.AdditionalInfo != "" {
+			fmt.Println("Additional info:", m.AdditionalInfo)
+		}
+		fmt.Println(m.Message)
+		fmt.Println()
+
+		if !view {
+			continue
+		}
+		if _, err := api.ChannelsViewSponsoredMessage(ctx, &tg.ChannelsViewSponsoredMessageRequest{
+			Channel:  channel,
+			RandomID: m.RandomID,
+		}); err != nil {
+			return errors.Wrap(err, "view sponsored message")
+		}
+	}
+	fmt.Println("Total sponsored messages:", len(sponsored.Messages))
+	if sponsored.PostsBetween > 0 {
+		fmt.Println("Posts between sponsored messages:", sponsored.PostsBetween)
+	}
+	return nil
+}
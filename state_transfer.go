@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	pebbledb "github.com/cockroachdb/pebble"
+	"github.com/go-faster/errors"
+)
+
+// exportState writes updates state of logged in account to file.
+func exportState(ctx context.Context, db *pebbledb.DB, state *BoltState, path string) error {
+	selfID, err := storedSelfID(db)
+	if err != nil {
+		return errors.Wrap(err, "self id")
+	}
+	if selfID == 0 {
+		return errors.New("account is unknown, log in first")
+	}
+	dump, err := state.Export(ctx, selfID)
+	if err != nil {
+		return errors.Wrap(err, "export")
+	}
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.Wrap(err, "write")
+	}
+	fmt.Printf("Exported state of %d channels to %s\n", len(dump.Channels), path)
+	return nil
+}
+
+// importState replaces updates state of logged in account with state from
+// file, which should be exported from the same account.
+func importState(ctx context.Context, db *pebbledb.DB, state *BoltState, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "read")
+	}
+	var dump StateDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return errors.Wrap(err, "unmarshal")
+	}
+	selfID, err := storedSelfID(db)
+	if err != nil {
+		return errors.Wrap(err, "self id")
+	}
+	if selfID == 0 {
+		// Session can be copied, but account is checked only after login.
+		return errors.New("account is unknown, log in first")
+	}
+	if dump.UserID != selfID {
+		return errors.Errorf("state is of account %d, but logged in as %d", dump.UserID, selfID)
+	}
+	if err := state.Import(ctx, dump); err != nil {
+		return errors.Wrap(err, "import")
+	}
+	fmt.Printf("Imported state of %d channels from %s\n", len(dump.Channels), path)
+	return nil
+}
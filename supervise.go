@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/gotd/td/telegram/auth"
+	"go.uber.org/zap"
+)
+
+// superviseHealthyRun is minimum duration of run after which it is
+// considered healthy, resetting restart counter and backoff.
+const superviseHealthyRun = time.Minute
+
+// superviseUpdates calls run until it succeeds, restarting it with
+// exponential backoff on recoverable errors at most maxRestarts times in
+// a row, so occasional failures of long-running handler do not exhaust
+// the limit.
+//
+// Context cancellation and authorization errors are not recoverable, so
+// they are returned as-is.
+func superviseUpdates(ctx context.Context, lg *zap.Logger, maxRestarts int, run func(ctx context.Context) error) error {
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = 0 // retry limit is controlled by maxRestarts
+
+	for restarts := 0; ; restarts++ {
+		start := time.Now()
+		err := run(ctx)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if auth.IsUnauthorized(err) || auth.IsKeyUnregistered(err) {
+			return err
+		}
+		if time.Since(start) >= superviseHealthyRun {
+			restarts = 0
+			b.Reset()
+		}
+		if restarts >= maxRestarts {
+			return err
+		}
+
+		wait := b.NextBackOff()
+		lg.Warn("Updates handler failed, restarting",
+			zap.Error(err),
+			zap.Int("restart", restarts+1),
+			zap.Duration("backoff", wait),
+		)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
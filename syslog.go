@@ -0,0 +1,31 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"log/syslog"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// newSyslogCore creates zap core that writes logs to syslog.
+//
+// Address is like "udp://host:514" or "tcp://host:514", local syslog is
+// used if address is empty.
+func newSyslogCore(addr string, enc zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	var network, raddr string
+	if addr != "" {
+		var ok bool
+		network, raddr, ok = strings.Cut(addr, "://")
+		if !ok || (network != "udp" && network != "tcp") {
+			return nil, errors.Errorf("invalid syslog address %q", addr)
+		}
+	}
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, "gotd-example")
+	if err != nil {
+		return nil, errors.Wrap(err, "dial syslog")
+	}
+	return zapcore.NewCore(enc, zapcore.AddSync(w), level), nil
+}
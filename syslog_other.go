@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package main
+
+import (
+	"github.com/go-faster/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// newSyslogCore is not supported on this platform.
+func newSyslogCore(addr string, enc zapcore.Encoder, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
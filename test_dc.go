@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// testPhoneDC returns DC of phone on test servers.
+//
+// Test phones are like 99966XYYYY, where X is DC ID and YYYY is random
+// number. Login code is X repeated, so no SMS is sent.
+func testPhoneDC(phone string) (int, error) {
+	digits := strings.TrimPrefix(phone, "+")
+	if len(digits) != 10 || !strings.HasPrefix(digits, "99966") {
+		return 0, errors.Errorf("invalid test phone %q: expected 99966XYYYY, where X is DC ID", phone)
+	}
+	dc := int(digits[5] - '0')
+	if dc < 1 || dc > 3 {
+		return 0, errors.Errorf("invalid test phone %q: test DC should be 1, 2 or 3", phone)
+	}
+	return dc, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/message/html"
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/gotd/td/telegram/query/dialogs"
+	"github.com/gotd/td/tg"
+)
+
+// parseText parses text with given parse mode (markdown or html) to styled
+// text. Plain text is returned as is if mode is empty.
+//
+// Users mentioned by ID in HTML are looked up in peer storage.
+func parseText(ctx context.Context, peerDB storage.PeerStorage, mode, text string) ([]styling.StyledTextOption, error) {
+	switch mode {
+	case "":
+		return []styling.StyledTextOption{styling.Plain(text)}, nil
+	case "markdown":
+		return parseMarkdown(text), nil
+	case "html":
+		return []styling.StyledTextOption{html.String(func(id int64) (tg.InputUserClass, error) {
+			p, err := peerDB.Find(ctx, storage.PeerKey{Kind: dialogs.User, ID: id})
+			if err != nil {
+				return nil, errors.Wrapf(err, "find user %d", id)
+			}
+			u, ok := p.AsInputUser()
+			if !ok {
+				return nil, errors.Errorf("peer %d is not user", id)
+			}
+			return u, nil
+		}, text)}, nil
+	default:
+		return nil, errors.Errorf("unknown parse mode %q", mode)
+	}
+}
+
+// parseMarkdown parses subset of Markdown: **bold**, *italic* or _italic_,
+// ~~strike~~, `code`, ```pre``` and [text](url).
+//
+// Unclosed markup is kept as plain text.
+func parseMarkdown(s string) []styling.StyledTextOption {
+	var (
+		r     []styling.StyledTextOption
+		plain strings.Builder
+	)
+	flush := func() {
+		if plain.Len() > 0 {
+			r = append(r, styling.Plain(plain.String()))
+			plain.Reset()
+		}
+	}
+	// enclosed returns text between delimiters at start of s and length of
+	// markup, if closed.
+	enclosed := func(s, delim string) (string, int, bool) {
+		if !strings.HasPrefix(s, delim) {
+			return "", 0, false
+		}
+		end := strings.Index(s[len(delim):], delim)
+		if end <= 0 {
+			return "", 0, false
+		}
+		return s[len(delim) : len(delim)+end], end + 2*len(delim), true
+	}
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		if text, n, ok := enclosed(rest, "```"); ok {
+			// Optional language on the first line.
+			var lang string
+			if first, body, found := strings.Cut(text, "\n"); found && !strings.ContainsAny(first, " \t") {
+				lang, text = first, body
+			}
+			flush()
+			r = append(r, styling.Pre(text, lang))
+			i += n
+			continue
+		}
+		if text, n, ok := enclosed(rest, "`"); ok {
+			flush()
+			r = append(r, styling.Code(text))
+			i += n
+			continue
+		}
+		if text, n, ok := enclosed(rest, "**"); ok {
+			flush()
+			r = append(r, styling.Bold(text))
+			i += n
+			continue
+		}
+		if text, n, ok := enclosed(rest, "~~"); ok {
+			flush()
+			r = append(r, styling.Strike(text))
+			i += n
+			continue
+		}
+		if text, n, ok := enclosed(rest, "*"); ok {
+			flush()
+			r = append(r, styling.Italic(text))
+			i += n
+			continue
+		}
+		if text, n, ok := enclosed(rest, "_"); ok {
+			flush()
+			r = append(r, styling.Italic(text))
+			i += n
+			continue
+		}
+		if strings.HasPrefix(rest, "[") {
+			if mid := strings.Index(rest, "]("); mid > 1 {
+				if end := strings.IndexByte(rest[mid:], ')'); end > 2 {
+					flush()
+					r = append(r, styling.TextURL(rest[1:mid], rest[mid+2:mid+end]))
+					i += mid + end + 1
+					continue
+				}
+			}
+		}
+		plain.WriteByte(s[i])
+		i++
+	}
+	flush()
+	return r
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/dcs"
+	"nhooyr.io/websocket"
+)
+
+// parseTLSPin parses SHA256 fingerprint in hex (colons are allowed) or
+// base64 encoding.
+func parseTLSPin(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if pin, err := hex.DecodeString(strings.ReplaceAll(s, ":", "")); err == nil && len(pin) == sha256.Size {
+		return pin, nil
+	}
+	if pin, err := base64.StdEncoding.DecodeString(s); err == nil && len(pin) == sha256.Size {
+		return pin, nil
+	}
+	return nil, errors.Errorf("invalid TLS pin %q: expected SHA256 in hex or base64", s)
+}
+
+// verifyPin returns function that checks that certificate chain of server
+// contains certificate or public key with pinned SHA256 fingerprint.
+//
+// Pinning is done in addition to regular verification of chain.
+func verifyPin(pin []byte) func(cs tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.Errorf("TLS pin mismatch for %s: no certificates", cs.ServerName)
+		}
+		for _, cert := range cs.PeerCertificates {
+			certSum := sha256.Sum256(cert.Raw)
+			keySum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			if bytes.Equal(certSum[:], pin) || bytes.Equal(keySum[:], pin) {
+				return nil
+			}
+		}
+		leaf := cs.PeerCertificates[0]
+		keySum := sha256.Sum256(leaf.RawSubjectPublicKeyInfo)
+		return errors.Errorf("TLS pin mismatch for %s: got certificate %q with key %s, possible MITM",
+			cs.ServerName, leaf.Subject, hex.EncodeToString(keySum[:]),
+		)
+	}
+}
+
+// websocketResolver returns resolver that connects to DCs via WebSocket
+// over TLS, using given proxy or proxy from HTTPS_PROXY env if nil.
+//
+// If pin is not nil, server certificate is verified against it.
+func websocketResolver(pin []byte, proxyURL *url.URL) dcs.Resolver {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if pin != nil {
+		cfg.VerifyConnection = verifyPin(pin)
+	}
+	proxy := http.ProxyFromEnvironment
+	if proxyURL != nil {
+		proxy = http.ProxyURL(proxyURL)
+	}
+	return dcs.Websocket(dcs.WebsocketOptions{
+		DialOptions: &websocket.DialOptions{
+			HTTPClient: &http.Client{
+				Transport: &http.Transport{
+					Proxy:           proxy,
+					TLSClientConfig: cfg,
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// translateErr converts translation errors to more descriptive ones.
+func translateErr(err error) error {
+	switch {
+	case tgerr.Is(err, "TO_LANG_INVALID"):
+		return errors.New("invalid target language")
+	case tgerr.Is(err, "TRANSLATE_REQ_QUOTA_EXCEEDED"):
+		return errors.New("translation quota exceeded, try later or get Telegram Premium")
+	case tgerr.Is(err, "PREMIUM_ACCOUNT_REQUIRED"):
+		return errors.New("translation requires Telegram Premium")
+	default:
+		return errors.Wrap(err, "translate")
+	}
+}
+
+// translateMessage translates message with given ID to target language and
+// prints it alongside original.
+func translateMessage(ctx context.Context, api *tg.Client, p tg.InputPeerClass, msgID int, lang string) error {
+	res, err := api.MessagesTranslateText(ctx, &tg.MessagesTranslateTextRequest{
+		Peer:   p,
+		ID:     []int{msgID},
+		ToLang: lang,
+	})
+	if err != nil {
+		return translateErr(err)
+	}
+	if len(res.Result) == 0 {
+		return errors.New("empty translation")
+	}
+
+	original := ""
+	if msgs, err := getMessages(ctx, api, p, msgID); err == nil && len(msgs) > 0 {
+		if msg, ok := msgs[0].(*tg.Message); ok {
+			original = msg.Message
+		}
+	}
+	if original != "" {
+		fmt.Println("Original:", original)
+	}
+	fmt.Printf("Translation (%s): %s\n", lang, res.Result[0].Text)
+	return nil
+}
+
+// getMessages fetches messages by ID from given peer.
+func getMessages(ctx context.Context, api *tg.Client, p tg.InputPeerClass, ids ...int) ([]tg.MessageClass, error) {
+	input := make([]tg.InputMessageClass, 0, len(ids))
+	for _, id := range ids {
+		input = append(input, &tg.InputMessageID{ID: id})
+	}
+
+	var (
+		res tg.MessagesMessagesClass
+		err error
+	)
+	if channel, ok := peer.ToInputChannel(p); ok {
+		// Channel messages have separate ID sequence.
+		res, err = api.ChannelsGetMessages(ctx, &tg.ChannelsGetMessagesRequest{
+			Channel: channel,
+			ID:      input,
+		})
+	} else {
+		res, err = api.MessagesGetMessages(ctx, input)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get messages")
+	}
+	modified, ok := res.AsModified()
+	if !ok {
+		return nil, errors.Errorf("unexpected response %T", res)
+	}
+	return modified.GetMessages(), nil
+}
+
+// autoTranslator translates incoming messages on the fly.
+type autoTranslator struct {
+	api     *tg.Client
+	lang    string
+	limiter *rate.Limiter
+}
+
+// Translate translates given text to target language.
+//
+// Returns false if translation is skipped due to rate limit or if text is
+// already in target language.
+func (t *autoTranslator) Translate(ctx context.Context, text string) (string, bool, error) {
+	if text == "" {
+		return "", false, nil
+	}
+	if !t.limiter.Allow() {
+		// Not blocking updates handling, skipping translation instead.
+		return "", false, nil
+	}
+	res, err := t.api.MessagesTranslateText(ctx, &tg.MessagesTranslateTextRequest{
+		Text:   []tg.TextWithEntities{{Text: text}},
+		ToLang: t.lang,
+	})
+	if err != nil {
+		return "", false, translateErr(err)
+	}
+	if len(res.Result) == 0 || res.Result[0].Text == text {
+		return "", false, nil
+	}
+	return res.Result[0].Text, true, nil
+}
+
+// Print translates and prints text of incoming message from given peer.
+//
+// Translation errors are logged, so failed translation does not fail
+// message handling.
+func (t *autoTranslator) Print(ctx context.Context, lg *zap.Logger, from fmt.Stringer, text string) {
+	translated, ok, err := t.Translate(ctx, text)
+	if err != nil {
+		lg.Warn("Auto-translate failed", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	fmt.Printf("%s [%s]: %s\n", from, t.lang, translated)
+}
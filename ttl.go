@@ -0,0 +1,18 @@
+package main
+
+import "github.com/gotd/td/tg"
+
+// mediaTTL returns self-destruct timer of message media, if any.
+//
+// Only photos and documents (including videos) can have TTL, plain text
+// messages can't self-destruct outside of secret chats.
+func mediaTTL(msg *tg.Message) (int, bool) {
+	type ttlMedia interface {
+		GetTTLSeconds() (int, bool)
+	}
+	m, ok := msg.Media.(ttlMedia)
+	if !ok {
+		return 0, false
+	}
+	return m.GetTTLSeconds()
+}
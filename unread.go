@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/tg"
+)
+
+// printMessages prints messages from API result with title.
+func printMessages(title string, res tg.MessagesMessagesClass) {
+	modified, ok := res.AsModified()
+	if !ok || len(modified.GetMessages()) == 0 {
+		fmt.Printf("%s: none\n", title)
+		return
+	}
+	fmt.Printf("%s:\n", title)
+	for _, m := range modified.GetMessages() {
+		msg, ok := m.(*tg.Message)
+		if !ok {
+			continue
+		}
+		date := time.Unix(int64(msg.Date), 0).Format(time.DateTime)
+		fmt.Printf("  [%d] %s: %s\n", msg.ID, date, msg.Message)
+	}
+}
+
+// printUnread prints unread mentions and reactions in dialog with given peer.
+func printUnread(ctx context.Context, api *tg.Client, p tg.InputPeerClass) error {
+	mentions, err := api.MessagesGetUnreadMentions(ctx, &tg.MessagesGetUnreadMentionsRequest{
+		Peer:  p,
+		Limit: 100,
+	})
+	if err != nil {
+		return errors.Wrap(err, "get unread mentions")
+	}
+	printMessages("Unread mentions", mentions)
+
+	reactions, err := api.MessagesGetUnreadReactions(ctx, &tg.MessagesGetUnreadReactionsRequest{
+		Peer:  p,
+		Limit: 100,
+	})
+	if err != nil {
+		return errors.Wrap(err, "get unread reactions")
+	}
+	printMessages("Unread reactions", reactions)
+
+	return nil
+}
+
+// readMentions marks all mentions in dialog with given peer as read.
+func readMentions(ctx context.Context, api *tg.Client, p tg.InputPeerClass) error {
+	// Server processes mentions in chunks, so request should be repeated
+	// until offset is zero.
+	for {
+		affected, err := api.MessagesReadMentions(ctx, &tg.MessagesReadMentionsRequest{
+			Peer: p,
+		})
+		if err != nil {
+			return errors.Wrap(err, "read mentions")
+		}
+		if affected.Offset == 0 {
+			break
+		}
+	}
+	fmt.Println("Mentions marked as read")
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// maxUsersBatch is maximum number of users in single users.getUsers call.
+const maxUsersBatch = 100
+
+type userRequest struct {
+	id    int64
+	input tg.InputUserClass
+	done  chan struct{}
+	user  *tg.User
+	err   error
+}
+
+// userBatcher resolves unknown users in batches.
+//
+// Requests are collected within short window and resolved by single
+// users.getUsers call, so burst of unknown senders in busy chat does not
+// lead to burst of calls.
+type userBatcher struct {
+	// ctx is base context of batched calls, so they are canceled on
+	// shutdown.
+	ctx    context.Context
+	api    *tg.Client
+	peerDB storage.PeerStorage
+	lg     *zap.Logger
+	window time.Duration
+
+	mux     sync.Mutex
+	pending []*userRequest
+}
+
+func newUserBatcher(ctx context.Context, api *tg.Client, peerDB storage.PeerStorage, lg *zap.Logger, window time.Duration) *userBatcher {
+	return &userBatcher{
+		ctx:    ctx,
+		api:    api,
+		peerDB: peerDB,
+		lg:     lg,
+		window: window,
+	}
+}
+
+// enqueue adds request to current batch, b.mux should be held.
+func (b *userBatcher) enqueue(r *userRequest) {
+	b.pending = append(b.pending, r)
+	switch len(b.pending) {
+	case 1:
+		// First request of batch.
+		time.AfterFunc(b.window, b.flush)
+	case maxUsersBatch:
+		go b.flush()
+	}
+}
+
+func (b *userBatcher) flush() {
+	b.mux.Lock()
+	batch := b.pending
+	if len(batch) > maxUsersBatch {
+		batch = batch[:maxUsersBatch]
+	}
+	b.pending = b.pending[len(batch):]
+	if len(b.pending) > 0 {
+		// Rest of requests is resolved in next batch.
+		time.AfterFunc(b.window, b.flush)
+	}
+	b.mux.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, time.Minute)
+	defer cancel()
+
+	input := make([]tg.InputUserClass, 0, len(batch))
+	for _, r := range batch {
+		input = append(input, r.input)
+	}
+	users, err := b.api.UsersGetUsers(ctx, input)
+	b.lg.Debug("Resolved users batch", zap.Int("count", len(batch)), zap.Error(err))
+
+	byID := map[int64]*tg.User{}
+	for _, u := range users {
+		u, ok := u.AsNotEmpty()
+		if !ok {
+			continue
+		}
+		byID[u.ID] = u
+
+		var p storage.Peer
+		if !p.FromUser(u) {
+			continue
+		}
+		if err := b.peerDB.Add(ctx, p); err != nil {
+			b.lg.Warn("Store resolved user", zap.Int64("user_id", u.ID), zap.Error(err))
+		}
+	}
+	for _, r := range batch {
+		switch u, ok := byID[r.id]; {
+		case err != nil:
+			r.err = errors.Wrap(err, "get users")
+		case !ok:
+			r.err = errors.Errorf("user %d not found", r.id)
+		default:
+			r.user = u
+		}
+		close(r.done)
+	}
+}
+
+// senderName returns name of message sender in given chat, resolving
+// unknown user via batcher, or empty string if sender is the chat itself,
+// like in private chats and channel posts.
+func (b *userBatcher) senderName(ctx context.Context, e tg.Entities, chat tg.InputPeerClass, msg *tg.Message) string {
+	switch from := msg.FromID.(type) {
+	case *tg.PeerUser:
+		if peer, ok := msg.PeerID.(*tg.PeerUser); ok && peer.UserID == from.UserID {
+			return ""
+		}
+		if u, ok := e.Users[from.UserID]; ok && !u.Min {
+			return userName(u)
+		}
+		if p, err := storage.FindPeer(ctx, b.peerDB, from); err == nil && p.User != nil {
+			return userName(p.User)
+		}
+		// Not waiting for resolve, so handler is not blocked and senders
+		// of subsequent messages are resolved in the same batch. Resolved
+		// user is stored, so name is printed for next messages.
+		b.resolveAsync(from.UserID, &tg.InputUserFromMessage{
+			Peer:   chat,
+			MsgID:  msg.ID,
+			UserID: from.UserID,
+		})
+		if u, ok := e.Users[from.UserID]; ok {
+			// Min user still has name.
+			return userName(u)
+		}
+		return fmt.Sprintf("user %d", from.UserID)
+	case *tg.PeerChannel:
+		if peer, ok := msg.PeerID.(*tg.PeerChannel); ok && peer.ChannelID == from.ChannelID {
+			// Anonymous admin of group.
+			if msg.PostAuthor != "" {
+				return msg.PostAuthor + " (anonymous admin)"
+			}
+			return "anonymous admin"
+		}
+		// Message sent on behalf of another channel.
+		if c, ok := e.Channels[from.ChannelID]; ok {
+			return c.Title
+		}
+		return fmt.Sprintf("channel %d", from.ChannelID)
+	default:
+		return ""
+	}
+}
+
+// resolveAsync enqueues resolve of user without waiting for result.
+func (b *userBatcher) resolveAsync(id int64, input tg.InputUserClass) {
+	r := &userRequest{id: id, input: input, done: make(chan struct{})}
+
+	b.mux.Lock()
+	for _, p := range b.pending {
+		if p.id == id {
+			// Already in batch.
+			b.mux.Unlock()
+			return
+		}
+	}
+	b.enqueue(r)
+	b.mux.Unlock()
+
+	go func() {
+		<-r.done
+		if r.err != nil {
+			b.lg.Debug("Resolve sender", zap.Int64("user_id", id), zap.Error(r.err))
+		}
+	}()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/contrib/storage"
+	"github.com/gotd/td/telegram/updates"
+	"github.com/gotd/td/tg"
+)
+
+// errWaitDone is returned when message awaited by -wait-for arrives.
+var errWaitDone = errors.New("awaited message received")
+
+// waitMatch is matched message with pts of its update.
+type waitMatch struct {
+	channelID int64 // zero for common updates sequence
+	pts       int
+}
+
+// waitFor waits for incoming message matching pattern.
+type waitFor struct {
+	pattern *regexp.Regexp
+	peers   peerSet
+	matched chan waitMatch
+}
+
+func newWaitFor(pattern, peers string) (*waitFor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile pattern")
+	}
+	w := &waitFor{
+		pattern: re,
+		matched: make(chan waitMatch, 1),
+	}
+	if peers != "" {
+		w.peers = parsePeerSet(peers)
+	}
+	return w, nil
+}
+
+// Match checks incoming message from peer, notifying waiter if it matches.
+func (w *waitFor) Match(p storage.Peer, msg *tg.Message, m waitMatch) {
+	if msg.Out {
+		return
+	}
+	if w.peers != nil && !w.peers.Contains(p) {
+		return
+	}
+	if !w.pattern.MatchString(msg.Message) {
+		return
+	}
+	fmt.Printf("Matched %s: %s\n", p, msg.Message)
+	select {
+	case w.matched <- m:
+	default:
+		// Already matched.
+	}
+}
+
+// Run waits for match, returning errWaitDone after persisting pts of
+// matched update, so it is not handled again on next run.
+func (w *waitFor) Run(ctx context.Context, state updates.StateStorage, selfID int64, timeout time.Duration) error {
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeoutC:
+		return errors.Errorf("no matching message within %s", timeout)
+	case m := <-w.matched:
+		var err error
+		if m.channelID != 0 {
+			err = state.SetChannelPts(ctx, selfID, m.channelID, m.pts)
+		} else {
+			err = state.SetPts(ctx, selfID, m.pts)
+		}
+		if err != nil {
+			return errors.Wrap(err, "save state")
+		}
+		return errWaitDone
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// watchdogMinWindow is minimal window of watchdog. Pings are sent every
+// quarter of window with the same timeout.
+const watchdogMinWindow = 4 * time.Second
+
+// errWatchdog is returned by watchdog if connection is considered wedged.
+var errWatchdog = errors.New("watchdog: no updates and pings")
+
+// watchdog detects connection that appears alive but silently stops
+// receiving anything.
+//
+// Unlike internal reconnection of client, watchdog requires full client
+// restart.
+type watchdog struct {
+	// Unix nanoseconds of last activity, i.e. update or successful ping.
+	last atomic.Int64
+}
+
+// Touch records activity.
+func (w *watchdog) Touch() {
+	w.last.Store(time.Now().UnixNano())
+}
+
+// Handler wraps update handler to record updates as activity.
+func (w *watchdog) Handler(h telegram.UpdateHandler) telegram.UpdateHandler {
+	return telegram.UpdateHandlerFunc(func(ctx context.Context, u tg.UpdatesClass) error {
+		w.Touch()
+		return h.Handle(ctx, u)
+	})
+}
+
+// Run pings server periodically and returns errWatchdog if there were no
+// updates and no successful pings within window.
+func (w *watchdog) Run(ctx context.Context, lg *zap.Logger, window time.Duration, ping func(ctx context.Context) error) error {
+	w.Touch()
+	interval := window / 4
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := ping(pingCtx)
+		cancel()
+		if err == nil {
+			w.Touch()
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lg.Debug("Watchdog ping failed", zap.Error(err))
+
+		silence := time.Since(time.Unix(0, w.last.Load()))
+		if silence < window {
+			continue
+		}
+		lg.Warn("Watchdog: forcing client restart", zap.Duration("silence", silence))
+		return errWatchdog
+	}
+}
+
+// clientInvoker invokes requests via current client, so API can outlive
+// client that is re-created on restart.
+//
+// Client is replaced while other goroutines use API, so it is stored
+// atomically.
+type clientInvoker struct {
+	client atomic.Pointer[telegram.Client]
+}
+
+// Set sets current client.
+func (c *clientInvoker) Set(client *telegram.Client) {
+	c.client.Store(client)
+}
+
+func (c *clientInvoker) Invoke(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+	return c.client.Load().Invoke(ctx, input, output)
+}
+
+// Ping pings server via current client.
+func (c *clientInvoker) Ping(ctx context.Context) error {
+	return c.client.Load().Ping(ctx)
+}
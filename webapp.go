@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/tg"
+	"go.uber.org/zap"
+)
+
+// sendWebAppButton sends message with keyboard button that opens web app
+// at given url.
+//
+// Only bots can attach keyboards to messages, so this requires bot account.
+func sendWebAppButton(ctx context.Context, sender *message.Sender, p tg.InputPeerClass, appURL string) error {
+	if _, err := url.ParseRequestURI(appURL); err != nil {
+		return errors.Wrap(err, "parse web app url")
+	}
+	if _, err := sender.To(p).Row(&tg.KeyboardButtonSimpleWebView{
+		Text: "Open web app",
+		URL:  appURL,
+	}).Text(ctx, "Press the button below to open web app"); err != nil {
+		return errors.Wrap(err, "send")
+	}
+	return nil
+}
+
+// validateWebAppData checks signature of web app init data using bot token
+// and returns parsed fields.
+//
+// See https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app.
+func validateWebAppData(initData, botToken string) (url.Values, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse")
+	}
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, errors.New("no hash")
+	}
+
+	// Data-check-string is all received fields except hash, sorted
+	// alphabetically and joined by line feed.
+	var fields []string
+	for k, v := range values {
+		if k == "hash" || len(v) == 0 {
+			continue
+		}
+		fields = append(fields, k+"="+v[0])
+	}
+	sort.Strings(fields)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(fields, "\n")))
+
+	expected, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode hash")
+	}
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return nil, errors.New("signature mismatch")
+	}
+	return values, nil
+}
+
+// printWebAppData prints data sent to bot by web app.
+//
+// Data relayed by Telegram is trusted, but if web app passes its init data
+// along (so bot could authenticate user), it is validated with bot token.
+func printWebAppData(lg *zap.Logger, action *tg.MessageActionWebViewDataSentMe, botToken string) {
+	lg.Info("Web app data",
+		zap.String("button", action.Text),
+		zap.String("data", action.Data),
+	)
+	fmt.Printf("Web app (%s) sent: %s\n", action.Text, action.Data)
+
+	if botToken == "" || !strings.Contains(action.Data, "hash=") {
+		return
+	}
+	values, err := validateWebAppData(action.Data, botToken)
+	if err != nil {
+		lg.Warn("Invalid web app data", zap.Error(err))
+		fmt.Println("Web app data signature is invalid:", err)
+		return
+	}
+	lg.Info("Web app data is valid", zap.String("user", values.Get("user")))
+}